@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"fmt"
+	"text/tabwriter"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/nicjohnson145/skeley/internal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Cache() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "cache",
+		Short: "Manage cached git template repositories",
+	}
+
+	rootCmd.AddCommand(
+		CacheList(),
+		CacheClean(),
+	)
+
+	return rootCmd
+}
+
+func CacheList() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List cached git template repositories",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			repos, err := internal.NewRepoCache(log, viper.GetString(config.CacheDir)).List()
+			if err != nil {
+				return err
+			}
+
+			tw := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 4, 2, ' ', 0)
+			for _, r := range repos {
+				fmt.Fprintf(tw, "%v\t%v\n", r.URL, r.Path)
+			}
+
+			return tw.Flush()
+		},
+	}
+
+	return rootCmd
+}
+
+func CacheClean() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "clean [URL...]",
+		Short: "Remove cached git template repositories, all of them if no URLs are given",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			repos, err := internal.NewRepoCache(log, viper.GetString(config.CacheDir)).Clean(args...)
+			if err != nil {
+				return err
+			}
+
+			for _, r := range repos {
+				fmt.Fprintf(cmd.OutOrStdout(), "removed %v\n", r.URL)
+			}
+
+			return nil
+		},
+	}
+
+	return rootCmd
+}
@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/nicjohnson145/skeley/internal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Diff() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "diff [DIR]",
+		Short: "Show how a generated project differs from its template",
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			format, err := config.ParseOutputFormat(viper.GetString(config.Output))
+			if err != nil {
+				return err
+			}
+
+			dir := config.DefaultOutputDirectory
+			if len(args) > 0 {
+				dir = args[0]
+			}
+			manifest, err := internal.ReadManifest(dir)
+			if err != nil {
+				return err
+			}
+
+			sourceConf, err := internal.RecordedSourceConfig(manifest.Source)
+			if err != nil {
+				return err
+			}
+			if manifest.Source.Commit != "" {
+				// Compare against the version the project was generated from, not the latest
+				sourceConf.Ref = manifest.Source.Commit
+			}
+			source, err := internal.LoadSource(log, sourceConf)
+			if err != nil {
+				return err
+			}
+
+			inputFS, err := fs.Sub(source.FS, manifest.Template)
+			if err != nil {
+				return err
+			}
+
+			values, err := recordedValues(cmd, manifest)
+			if err != nil {
+				return err
+			}
+
+			var prompter *internal.Prompter
+			if !viper.GetBool(config.NoInput) && internal.IsTerminal(cmd.InOrStdin()) {
+				prompter = internal.NewPrompter(cmd.InOrStdin(), cmd.ErrOrStderr())
+			}
+
+			skeley := internal.NewSkeley(internal.SkeleyConfig{
+				Logger: log,
+				InputFS: inputFS,
+				SourceFS: source.FS,
+				SourceInfo: source.SourceInfo,
+				Template: manifest.Template,
+				OutputPath: dir,
+				Values: values,
+				Prompter: prompter,
+			})
+
+			drifted, err := skeley.Drift()
+			if err != nil {
+				return err
+			}
+
+			if err := internal.WriteDrift(cmd.OutOrStdout(), drifted, format); err != nil {
+				return err
+			}
+
+			if viper.GetBool(config.ExitCode) && len(drifted) > 0 {
+				return fmt.Errorf("%v files differ from the template", len(drifted))
+			}
+
+			return nil
+		},
+	}
+
+	rootCmd.Flags().Bool(config.ExitCode, false, "Exit non-zero if any files differ from the template")
+	rootCmd.Flags().String(config.Output, config.DefaultOutput.String(), "Output format, one of text for unified diffs or json to list the files that differ")
+	rootCmd.Flags().StringArray(config.Set, []string{}, "Set a template variable as key=value, can be repeated")
+	rootCmd.Flags().StringArray(config.Values, []string{}, "YAML or JSON file of template variable values, can be repeated")
+	rootCmd.Flags().Bool(config.NoInput, false, "Never prompt for secret variables, compare against the manifest's hashes if they have no value")
+
+	return rootCmd
+}
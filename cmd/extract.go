@@ -0,0 +1,32 @@
+package cmd
+
+import (
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/nicjohnson145/skeley/internal"
+	"github.com/spf13/cobra"
+)
+
+func Extract() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "extract <PROJECT_DIR> <[SOURCE/]TEMPLATE_NAME>",
+		Short: "Create a template from an existing Go project",
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			sources, err := internal.SourcesFromEnv(log)
+			if err != nil {
+				return err
+			}
+
+			templateDir, err := sources.NewTemplateDir(args[1])
+			if err != nil {
+				return err
+			}
+
+			return internal.ExtractTemplate(log, args[0], templateDir)
+		},
+	}
+
+	return rootCmd
+}
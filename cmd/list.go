@@ -1,9 +1,6 @@
 package cmd
 
 import (
-	"fmt"
-	"os"
-
 	"github.com/nicjohnson145/skeley/config"
 	"github.com/nicjohnson145/skeley/internal"
 	"github.com/spf13/cobra"
@@ -13,28 +10,37 @@ import (
 func List() *cobra.Command {
 	rootCmd := &cobra.Command{
 		Use:   "list",
-		Short: "List available local templates",
+		Short: "List available templates",
 		Args: cobra.ExactArgs(0),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			log := config.InitLogger()
 
-			skeley := internal.NewSkeley(internal.SkeleyConfig{
-				Logger: log,
-				InputFS: os.DirFS(viper.GetString(config.TemplateDir)),
-			})
+			format, err := config.ParseOutputFormat(viper.GetString(config.Output))
+			if err != nil {
+				return err
+			}
 
-			tmpls, err := skeley.ListTemplates()
+			tags, err := cmd.Flags().GetStringArray(config.Tag)
 			if err != nil {
 				return err
 			}
 
-			for _, t := range tmpls {
-				fmt.Fprintln(cmd.OutOrStdout(), t)
+			sources, err := internal.SourcesFromEnv(log)
+			if err != nil {
+				return err
 			}
 
-			return nil
+			tmpls, err := sources.ListTemplates()
+			if err != nil {
+				return err
+			}
+
+			return internal.WriteTemplates(cmd.OutOrStdout(), internal.FilterTemplates(tmpls, tags), format)
 		},
 	}
 
+	rootCmd.Flags().StringArray(config.Tag, []string{}, "Only list templates with this tag, can be repeated")
+	rootCmd.Flags().String(config.Output, config.DefaultOutput.String(), "Output format, one of text or json")
+
 	return rootCmd
 }
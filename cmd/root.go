@@ -1,15 +1,18 @@
 package cmd
 
 import (
+	"io/fs"
+
 	"github.com/nicjohnson145/skeley/config"
 	"github.com/nicjohnson145/skeley/internal"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 )
 
-func Root() *cobra.Command {
+func Root(version string) *cobra.Command {
 	rootCmd := &cobra.Command{
-		Use:   "skeley [OPTS] <TEMPLATE_NAME>",
+		Version: version,
+		Use:   "skeley [OPTS] <[SOURCE/]TEMPLATE_NAME>",
 		Short: "Execute directory templates",
 		Args: cobra.ExactArgs(1),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
@@ -22,27 +25,113 @@ func Root() *cobra.Command {
 		RunE: func(cmd *cobra.Command, args []string) error {
 			log := config.InitLogger()
 
-			inputFS, err := internal.InputFSFromEnv(log, args[0])
+			sources, err := internal.SourcesFromEnv(log)
+			if err != nil {
+				return err
+			}
+
+			source, template, err := sources.Find(args[0])
+			if err != nil {
+				return err
+			}
+
+			inputFS, err := fs.Sub(source.FS, template)
+			if err != nil {
+				return err
+			}
+
+			valueFiles, err := cmd.Flags().GetStringArray(config.Values)
+			if err != nil {
+				return err
+			}
+			sets, err := cmd.Flags().GetStringArray(config.Set)
+			if err != nil {
+				return err
+			}
+			values, err := internal.LoadValues(valueFiles, sets)
 			if err != nil {
 				return err
 			}
 
+			onConflict, err := config.ParseConflictPolicy(viper.GetString(config.OnConflict))
+			if err != nil {
+				return err
+			}
+
+			// Read from the flags rather than viper, so common env vars like $GO_VERSION aren't picked up
+			module, err := cmd.Flags().GetString(config.Module)
+			if err != nil {
+				return err
+			}
+			goVersion, err := cmd.Flags().GetString(config.GoVersion)
+			if err != nil {
+				return err
+			}
+
+			var prompter *internal.Prompter
+			if !viper.GetBool(config.NoInput) && internal.IsTerminal(cmd.InOrStdin()) {
+				prompter = internal.NewPrompter(cmd.InOrStdin(), cmd.ErrOrStderr())
+			}
+
 			skeley := internal.NewSkeley(internal.SkeleyConfig{
 				Logger: config.InitLogger(),
 				InputFS: inputFS,
+				SourceFS: source.FS,
+				SourceInfo: source.SourceInfo,
+				Template: template,
 				OutputPath: viper.GetString(config.OutputDirectory),
+				Values: values,
+				Prompter: prompter,
+				NoInput: viper.GetBool(config.NoInput),
+				OnConflict: onConflict,
+				Version: version,
+				Module: module,
+				GoVersion: goVersion,
 			})
-			return skeley.Execute()
+
+			if !viper.GetBool(config.DryRun) {
+				return skeley.Execute()
+			}
+
+			format, err := config.ParseOutputFormat(viper.GetString(config.Output))
+			if err != nil {
+				return err
+			}
+
+			plan, err := skeley.Plan()
+			if err != nil {
+				return err
+			}
+
+			return internal.WritePlan(cmd.OutOrStdout(), plan, format)
 		},
 	}
 	rootCmd.PersistentFlags().BoolP(config.Debug, "d", config.DefaultDebug, "Enable debug logging")
-	rootCmd.PersistentFlags().StringP(config.TemplateDir, "t", "", "Override default template directory of '~/.config/skeley/templates'")
+	rootCmd.PersistentFlags().String(config.ConfigFile, "", "Override default user config file of '~/.config/skeley/config.yaml'")
+	rootCmd.PersistentFlags().StringP(config.TemplateDir, "t", "", "Use this template directory instead of the sources in the user config, or the default of '~/.config/skeley/templates'")
+	rootCmd.PersistentFlags().StringP(config.InputType, "i", config.DefaulInputType.String(), "Where to load the template from")
+	rootCmd.PersistentFlags().String(config.Ref, "", "Branch, tag or commit SHA to use from a git template source")
+	rootCmd.PersistentFlags().String(config.CacheDir, "", "Override default cache directory for git template sources of '<user cache dir>/skeley/repos'")
+	rootCmd.PersistentFlags().Bool(config.Offline, false, "Use the cached copy of a git template source without fetching it")
 
 	rootCmd.Flags().StringP(config.OutputDirectory, "o", config.DefaultOutputDirectory, "Where to output the rendered template")
-	rootCmd.Flags().StringP(config.InputType, "i", config.DefaulInputType.String(), "Where to load the template from")
+	rootCmd.Flags().StringArray(config.Set, []string{}, "Set a template variable as key=value, can be repeated")
+	rootCmd.Flags().StringArray(config.Values, []string{}, "YAML or JSON file of template variable values, can be repeated")
+	rootCmd.Flags().Bool(config.NoInput, false, "Never prompt for variables, fail if one has no value or default")
+	rootCmd.Flags().String(config.OnConflict, config.DefaultOnConflict.String(), "What to do with existing files that differ, one of overwrite, skip, error, prompt or backup")
+	rootCmd.Flags().Bool(config.DryRun, false, "Print what would be written without writing anything or running commands")
+	rootCmd.Flags().String(config.Output, config.DefaultOutput.String(), "Format of the --dry-run plan, one of text or json")
+	rootCmd.Flags().String(config.Module, "", "Module path for module templates, creating go.mod if the output directory has none")
+	rootCmd.Flags().String(config.GoVersion, "", "Go version of a go.mod created for --module, defaulting to the version skeley was built with")
 
 	rootCmd.AddCommand(
 		List(),
+		Show(),
+		Extract(),
+		Validate(),
+		Cache(),
+		Update(version),
+		Diff(),
 	)
 
 	return rootCmd
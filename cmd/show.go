@@ -0,0 +1,47 @@
+package cmd
+
+import (
+	"io/fs"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/nicjohnson145/skeley/internal"
+	"github.com/spf13/cobra"
+)
+
+func Show() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "show <TEMPLATE_NAME>",
+		Short: "Show the metadata, variables, hooks and README of a template",
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			sources, err := internal.SourcesFromEnv(log)
+			if err != nil {
+				return err
+			}
+
+			source, template, err := sources.Find(args[0])
+			if err != nil {
+				return err
+			}
+
+			inputFS, err := fs.Sub(source.FS, template)
+			if err != nil {
+				return err
+			}
+
+			skeley := internal.NewSkeley(internal.SkeleyConfig{
+				Logger: log,
+				InputFS: inputFS,
+				SourceFS: source.FS,
+				SourceInfo: source.SourceInfo,
+				Template: template,
+			})
+
+			return skeley.ShowTemplate(cmd.OutOrStdout())
+		},
+	}
+
+	return rootCmd
+}
@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/nicjohnson145/skeley/internal"
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+func Update(version string) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "update",
+		Short: "Merge changes from a newer version of the template into a generated project",
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			dir := viper.GetString(config.OutputDirectory)
+			manifest, err := internal.ReadManifest(dir)
+			if err != nil {
+				return err
+			}
+			if manifest.Source.Type != config.SourceTypeGit || manifest.Source.Commit == "" {
+				return fmt.Errorf("updating requires a project generated from a git template source, %v was generated from a %v source", dir, manifest.Source.Type)
+			}
+
+			sourceConf, err := internal.RecordedSourceConfig(manifest.Source)
+			if err != nil {
+				return err
+			}
+			if ref := viper.GetString(config.Ref); ref != "" {
+				sourceConf.Ref = ref
+			}
+			previousConf := sourceConf
+			previousConf.Ref = manifest.Source.Commit
+
+			previous, err := internal.LoadSource(log, previousConf)
+			if err != nil {
+				return err
+			}
+			source, err := internal.LoadSource(log, sourceConf)
+			if err != nil {
+				return err
+			}
+
+			inputFS, err := fs.Sub(source.FS, manifest.Template)
+			if err != nil {
+				return err
+			}
+
+			values, err := recordedValues(cmd, manifest)
+			if err != nil {
+				return err
+			}
+
+			var prompter *internal.Prompter
+			if !viper.GetBool(config.NoInput) && internal.IsTerminal(cmd.InOrStdin()) {
+				prompter = internal.NewPrompter(cmd.InOrStdin(), cmd.ErrOrStderr())
+			}
+
+			skeley := internal.NewSkeley(internal.SkeleyConfig{
+				Logger: log,
+				InputFS: inputFS,
+				SourceFS: source.FS,
+				SourceInfo: source.SourceInfo,
+				Template: manifest.Template,
+				OutputPath: dir,
+				Values: values,
+				Prompter: prompter,
+				NoInput: viper.GetBool(config.NoInput),
+				Version: version,
+			})
+
+			updated, err := skeley.Update(previous.FS)
+			if err != nil {
+				return err
+			}
+
+			if err := internal.WriteUpdate(cmd.OutOrStdout(), updated); err != nil {
+				return err
+			}
+
+			conflicts := 0
+			for _, f := range updated {
+				if f.Action == internal.UpdateActionConflict {
+					conflicts++
+				}
+			}
+			if conflicts > 0 {
+				return fmt.Errorf("%v files have conflicts with the template, resolve them before committing", conflicts)
+			}
+
+			return nil
+		},
+	}
+
+	rootCmd.Flags().StringP(config.OutputDirectory, "o", config.DefaultOutputDirectory, "Directory of the project to update")
+	rootCmd.Flags().StringArray(config.Set, []string{}, "Set a template variable as key=value, can be repeated")
+	rootCmd.Flags().StringArray(config.Values, []string{}, "YAML or JSON file of template variable values, can be repeated")
+	rootCmd.Flags().Bool(config.NoInput, false, "Never prompt for variables, fail if one has no value or default")
+
+	return rootCmd
+}
+
+// recordedValues loads the values given by flag, falling back to the answers recorded in the manifest
+func recordedValues(cmd *cobra.Command, manifest *internal.Manifest) (map[string]any, error) {
+	valueFiles, err := cmd.Flags().GetStringArray(config.Values)
+	if err != nil {
+		return nil, err
+	}
+	sets, err := cmd.Flags().GetStringArray(config.Set)
+	if err != nil {
+		return nil, err
+	}
+	values, err := internal.LoadValues(valueFiles, sets)
+	if err != nil {
+		return nil, err
+	}
+
+	for k, v := range manifest.Answers {
+		if _, ok := values[k]; !ok {
+			values[k] = v
+		}
+	}
+
+	return values, nil
+}
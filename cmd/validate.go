@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/nicjohnson145/skeley/internal"
+	"github.com/spf13/cobra"
+)
+
+func Validate() *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "validate [[SOURCE/]TEMPLATE_NAME...]",
+		Short: "Check templates for problems, all of them if none are given",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			log := config.InitLogger()
+
+			sources, err := internal.SourcesFromEnv(log)
+			if err != nil {
+				return err
+			}
+
+			names := args
+			if len(names) == 0 {
+				tmpls, err := sources.ListTemplates()
+				if err != nil {
+					return err
+				}
+				for _, t := range tmpls {
+					names = append(names, path.Join(t.Source, t.Template))
+				}
+			}
+
+			problems, failed := 0, 0
+			for _, name := range names {
+				source, template, err := sources.Find(name)
+				if err != nil {
+					return err
+				}
+
+				inputFS, err := fs.Sub(source.FS, template)
+				if err != nil {
+					return err
+				}
+
+				skeley := internal.NewSkeley(internal.SkeleyConfig{
+					Logger: log,
+					InputFS: inputFS,
+					SourceFS: source.FS,
+					SourceInfo: source.SourceInfo,
+					Template: template,
+				})
+
+				diags, err := skeley.Validate()
+				if err != nil {
+					return err
+				}
+
+				for _, d := range diags {
+					fmt.Fprintf(cmd.OutOrStdout(), "%v/%v\n", template, d)
+				}
+				problems += len(diags)
+				if len(diags) > 0 {
+					failed++
+				}
+			}
+
+			if problems > 0 {
+				return fmt.Errorf("found %v problems in %v templates", problems, failed)
+			}
+
+			return nil
+		},
+	}
+
+	return rootCmd
+}
@@ -20,6 +20,25 @@ git
 */
 type SourceType string
 
+/*
+ENUM(
+text
+json
+)
+*/
+type OutputFormat string
+
+/*
+ENUM(
+overwrite
+skip
+error
+prompt
+backup
+)
+*/
+type ConflictPolicy string
+
 const (
 	Debug           = "debug"
 	TemplateDir     = "template-dir"
@@ -29,12 +48,28 @@ const (
 	Token           = "token"
 	TokenUser       = "token-user"
 	BranchName      = "branch-name"
+	Ref             = "ref"
+	Set             = "set"
+	Values          = "values"
+	NoInput         = "no-input"
+	DryRun          = "dry-run"
+	Output          = "output"
+	OnConflict      = "on-conflict"
+	CacheDir        = "cache-dir"
+	Offline         = "offline"
+	Tag             = "tag"
+	ConfigFile      = "config"
+	ExitCode        = "exit-code"
+	Module          = "module"
+	GoVersion       = "go-version"
 )
 
 const (
 	DefaultDebug           = false
 	DefaultOutputDirectory = "."
 	DefaulInputType        = SourceTypeLocal
+	DefaultOutput          = OutputFormatText
+	DefaultOnConflict      = ConflictPolicyOverwrite
 )
 
 func InitializeConfig(cmd *cobra.Command) error {
@@ -46,11 +81,20 @@ func InitializeConfig(cmd *cobra.Command) error {
 		return fmt.Errorf("error getting user homedir: %w", err)
 	}
 
-	viper.SetDefault(TemplateDir, filepath.Join(home, ".config", "skeley", "templates"))
+	viper.SetDefault(ConfigFile, filepath.Join(home, ".config", "skeley", "config.yaml"))
+
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return fmt.Errorf("error getting user cache dir: %w", err)
+	}
+
+	viper.SetDefault(CacheDir, filepath.Join(cacheDir, "skeley", "repos"))
 
 	viper.SetDefault(Debug, DefaultDebug)
 	viper.SetDefault(OutputDirectory, DefaultOutputDirectory)
 	viper.SetDefault(InputType, DefaulInputType)
+	viper.SetDefault(Output, DefaultOutput)
+	viper.SetDefault(OnConflict, DefaultOnConflict)
 
 	viper.BindPFlags(cmd.Flags())
 
@@ -11,6 +11,174 @@ import (
 	"strings"
 )
 
+const (
+	// ConflictPolicyOverwrite is a ConflictPolicy of type overwrite.
+	ConflictPolicyOverwrite ConflictPolicy = "overwrite"
+	// ConflictPolicySkip is a ConflictPolicy of type skip.
+	ConflictPolicySkip ConflictPolicy = "skip"
+	// ConflictPolicyError is a ConflictPolicy of type error.
+	ConflictPolicyError ConflictPolicy = "error"
+	// ConflictPolicyPrompt is a ConflictPolicy of type prompt.
+	ConflictPolicyPrompt ConflictPolicy = "prompt"
+	// ConflictPolicyBackup is a ConflictPolicy of type backup.
+	ConflictPolicyBackup ConflictPolicy = "backup"
+)
+
+var ErrInvalidConflictPolicy = fmt.Errorf("not a valid ConflictPolicy, try [%s]", strings.Join(_ConflictPolicyNames, ", "))
+
+var _ConflictPolicyNames = []string{
+	string(ConflictPolicyOverwrite),
+	string(ConflictPolicySkip),
+	string(ConflictPolicyError),
+	string(ConflictPolicyPrompt),
+	string(ConflictPolicyBackup),
+}
+
+// ConflictPolicyNames returns a list of possible string values of ConflictPolicy.
+func ConflictPolicyNames() []string {
+	tmp := make([]string, len(_ConflictPolicyNames))
+	copy(tmp, _ConflictPolicyNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x ConflictPolicy) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x ConflictPolicy) IsValid() bool {
+	_, err := ParseConflictPolicy(string(x))
+	return err == nil
+}
+
+var _ConflictPolicyValue = map[string]ConflictPolicy{
+	"overwrite": ConflictPolicyOverwrite,
+	"skip":      ConflictPolicySkip,
+	"error":     ConflictPolicyError,
+	"prompt":    ConflictPolicyPrompt,
+	"backup":    ConflictPolicyBackup,
+}
+
+// ParseConflictPolicy attempts to convert a string to a ConflictPolicy.
+func ParseConflictPolicy(name string) (ConflictPolicy, error) {
+	if x, ok := _ConflictPolicyValue[name]; ok {
+		return x, nil
+	}
+	return ConflictPolicy(""), fmt.Errorf("%s is %w", name, ErrInvalidConflictPolicy)
+}
+
+// MarshalText implements the text marshaller method.
+func (x ConflictPolicy) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *ConflictPolicy) UnmarshalText(text []byte) error {
+	tmp, err := ParseConflictPolicy(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+// Set implements the Golang flag.Value interface func.
+func (x *ConflictPolicy) Set(val string) error {
+	v, err := ParseConflictPolicy(val)
+	*x = v
+	return err
+}
+
+// Get implements the Golang flag.Getter interface func.
+func (x *ConflictPolicy) Get() interface{} {
+	return *x
+}
+
+// Type implements the github.com/spf13/pFlag Value interface.
+func (x *ConflictPolicy) Type() string {
+	return "ConflictPolicy"
+}
+
+const (
+	// OutputFormatText is a OutputFormat of type text.
+	OutputFormatText OutputFormat = "text"
+	// OutputFormatJson is a OutputFormat of type json.
+	OutputFormatJson OutputFormat = "json"
+)
+
+var ErrInvalidOutputFormat = fmt.Errorf("not a valid OutputFormat, try [%s]", strings.Join(_OutputFormatNames, ", "))
+
+var _OutputFormatNames = []string{
+	string(OutputFormatText),
+	string(OutputFormatJson),
+}
+
+// OutputFormatNames returns a list of possible string values of OutputFormat.
+func OutputFormatNames() []string {
+	tmp := make([]string, len(_OutputFormatNames))
+	copy(tmp, _OutputFormatNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x OutputFormat) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x OutputFormat) IsValid() bool {
+	_, err := ParseOutputFormat(string(x))
+	return err == nil
+}
+
+var _OutputFormatValue = map[string]OutputFormat{
+	"text": OutputFormatText,
+	"json": OutputFormatJson,
+}
+
+// ParseOutputFormat attempts to convert a string to a OutputFormat.
+func ParseOutputFormat(name string) (OutputFormat, error) {
+	if x, ok := _OutputFormatValue[name]; ok {
+		return x, nil
+	}
+	return OutputFormat(""), fmt.Errorf("%s is %w", name, ErrInvalidOutputFormat)
+}
+
+// MarshalText implements the text marshaller method.
+func (x OutputFormat) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *OutputFormat) UnmarshalText(text []byte) error {
+	tmp, err := ParseOutputFormat(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
+
+// Set implements the Golang flag.Value interface func.
+func (x *OutputFormat) Set(val string) error {
+	v, err := ParseOutputFormat(val)
+	*x = v
+	return err
+}
+
+// Get implements the Golang flag.Getter interface func.
+func (x *OutputFormat) Get() interface{} {
+	return *x
+}
+
+// Type implements the github.com/spf13/pFlag Value interface.
+func (x *OutputFormat) Type() string {
+	return "OutputFormat"
+}
+
 const (
 	// SourceTypeLocal is a SourceType of type local.
 	SourceTypeLocal SourceType = "local"
@@ -0,0 +1,249 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/go-git/go-billy/v5"
+	billymem "github.com/go-git/go-billy/v5/memfs"
+	"github.com/go-git/go-billy/v5/osfs"
+	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/cache"
+	"github.com/go-git/go-git/v5/plumbing/transport"
+	"github.com/go-git/go-git/v5/storage/filesystem"
+	"github.com/rs/zerolog"
+)
+
+// cacheFetchSpecs mirror every branch and tag of the remote, along with its default branch
+var cacheFetchSpecs = []gitconfig.RefSpec{
+	"+HEAD:refs/remotes/origin/HEAD",
+	"+refs/heads/*:refs/remotes/origin/*",
+	"+refs/tags/*:refs/tags/*",
+}
+
+// CachedRepo is a template repository in the cache
+type CachedRepo struct {
+	URL  string `json:"url"`
+	Path string `json:"path"`
+}
+
+// RepoCache keeps bare copies of git template sources on disk, so later runs only fetch what changed and can work
+// offline
+type RepoCache struct {
+	log zerolog.Logger
+	dir string
+}
+
+func NewRepoCache(logger zerolog.Logger, dir string) *RepoCache {
+	return &RepoCache{
+		log: logger,
+		dir: dir,
+	}
+}
+
+// path is where the repo for url is cached
+func (c *RepoCache) path(url string) string {
+	return filepath.Join(c.dir, fmt.Sprintf("%x", sha256.Sum256([]byte(url)))[:16])
+}
+
+func (c *RepoCache) storage(path string) *filesystem.Storage {
+	return filesystem.NewStorage(osfs.New(path), cache.NewObjectLRUDefault())
+}
+
+// checkout opens the cached copy of url, fetching any changes unless offline, and checks out the ref or branch into an
+// in memory worktree, returning it with the full commit hash
+func (c *RepoCache) checkout(url string, auth transport.AuthMethod, ref string, branch string, offline bool) (billy.Filesystem, string, error) {
+	path := c.path(url)
+	workTree := billymem.New()
+
+	created := false
+	_, err := os.Stat(path)
+	switch {
+	case err == nil:
+	case errors.Is(err, fs.ErrNotExist):
+		if offline {
+			return nil, "", fmt.Errorf("no cached copy of %v, run without --offline first", url)
+		}
+
+		c.log.Debug().Str("path", path).Msg("initializing cached repo")
+		repo, err := git.Init(c.storage(path), nil)
+		if err != nil {
+			return nil, "", fmt.Errorf("error initializing cached repo: %w", err)
+		}
+		if _, err := repo.CreateRemote(&gitconfig.RemoteConfig{Name: git.DefaultRemoteName, URLs: []string{url}}); err != nil {
+			return nil, "", fmt.Errorf("error initializing cached repo: %w", err)
+		}
+		created = true
+	default:
+		return nil, "", fmt.Errorf("error reading cached repo: %w", err)
+	}
+
+	repo, err := git.Open(c.storage(path), workTree)
+	if err != nil {
+		return nil, "", fmt.Errorf("error opening cached repo: %w", err)
+	}
+
+	if offline {
+		c.log.Debug().Str("path", path).Msg("using cached repo without fetching")
+	} else {
+		c.log.Debug().Str("path", path).Msg("fetching into cached repo")
+		err := repo.Fetch(&git.FetchOptions{
+			RemoteName: git.DefaultRemoteName,
+			RefSpecs:   cacheFetchSpecs,
+			Auth:       auth,
+			Force:      true,
+		})
+		if err != nil && !errors.Is(err, git.NoErrAlreadyUpToDate) {
+			if created {
+				// Don't leave an empty repo behind to be mistaken for a cached copy
+				os.RemoveAll(path)
+			}
+			return nil, "", fmt.Errorf("error fetching repo: %w", err)
+		}
+	}
+
+	hash, err := resolveCachedRef(repo, ref, branch)
+	if err != nil {
+		return nil, "", err
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return nil, "", err
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true}); err != nil {
+		return nil, "", fmt.Errorf("error checking out %v: %w", hash, err)
+	}
+
+	return workTree, hash.String(), nil
+}
+
+// resolveCachedRef finds the commit for a branch, or a ref that may be a tag, branch or commit, preferring tags. With
+// neither, the remote's default branch is used
+func resolveCachedRef(repo *git.Repository, ref string, branch string) (*plumbing.Hash, error) {
+	var candidates []plumbing.ReferenceName
+	switch {
+	case branch != "":
+		candidates = []plumbing.ReferenceName{plumbing.NewRemoteReferenceName(git.DefaultRemoteName, branch)}
+	case ref != "":
+		candidates = []plumbing.ReferenceName{
+			plumbing.NewTagReferenceName(ref),
+			plumbing.NewRemoteReferenceName(git.DefaultRemoteName, ref),
+		}
+	default:
+		candidates = []plumbing.ReferenceName{plumbing.NewRemoteHEADReferenceName(git.DefaultRemoteName)}
+	}
+
+	for _, name := range candidates {
+		r, err := repo.Reference(name, true)
+		if errors.Is(err, plumbing.ErrReferenceNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading %v: %w", name, err)
+		}
+
+		// Resolve annotated tags down to their commit
+		hash, err := repo.ResolveRevision(plumbing.Revision(r.Hash().String()))
+		if err != nil {
+			return nil, fmt.Errorf("error resolving %v: %w", name, err)
+		}
+		return hash, nil
+	}
+
+	switch {
+	case branch != "":
+		return nil, fmt.Errorf("branch %v not found", branch)
+	case ref == "":
+		return nil, fmt.Errorf("remote has no default branch")
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return nil, fmt.Errorf("error resolving ref %v: %w", ref, err)
+	}
+	return hash, nil
+}
+
+// List returns the cached repos, sorted by URL
+func (c *RepoCache) List() ([]CachedRepo, error) {
+	entries, err := os.ReadDir(c.dir)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return []CachedRepo{}, nil
+		}
+		return nil, fmt.Errorf("error reading cache directory: %w", err)
+	}
+
+	repos := []CachedRepo{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		path := filepath.Join(c.dir, entry.Name())
+		repo, err := git.Open(c.storage(path), nil)
+		if err != nil {
+			c.log.Warn().Err(err).Str("path", path).Msg("skipping unreadable cache entry")
+			continue
+		}
+		remote, err := repo.Remote(git.DefaultRemoteName)
+		if err != nil {
+			c.log.Warn().Err(err).Str("path", path).Msg("skipping cache entry without a remote")
+			continue
+		}
+
+		repos = append(repos, CachedRepo{
+			URL:  remote.Config().URLs[0],
+			Path: path,
+		})
+	}
+
+	sort.Slice(repos, func(i, j int) bool {
+		return repos[i].URL < repos[j].URL
+	})
+
+	return repos, nil
+}
+
+// Clean removes the cached copies of the given URLs, or every cached repo if none are given. The removed repos are
+// returned
+func (c *RepoCache) Clean(urls ...string) ([]CachedRepo, error) {
+	repos, err := c.List()
+	if err != nil {
+		return nil, err
+	}
+
+	if len(urls) > 0 {
+		selected := []CachedRepo{}
+		for _, url := range urls {
+			found := false
+			for _, r := range repos {
+				if r.URL == url {
+					selected = append(selected, r)
+					found = true
+				}
+			}
+			if !found {
+				return nil, fmt.Errorf("%v is not cached", url)
+			}
+		}
+		repos = selected
+	}
+
+	for _, r := range repos {
+		c.log.Debug().Str("url", r.URL).Str("path", r.Path).Msg("removing cached repo")
+		if err := os.RemoveAll(r.Path); err != nil {
+			return nil, fmt.Errorf("error removing cached repo %v: %w", r.URL, err)
+		}
+	}
+
+	return repos, nil
+}
@@ -0,0 +1,165 @@
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"testing"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRepoCache(t *testing.T) {
+	readVersion := func(t *testing.T, source *Source) string {
+		t.Helper()
+		content, err := fs.ReadFile(source.FS, "example/files/VERSION")
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	useCache := func(t *testing.T, url string, cacheDir string) {
+		t.Cleanup(viper.Reset)
+		viper.Set(config.InputType, config.SourceTypeGit)
+		viper.Set(config.TemplateDir, url)
+		viper.Set(config.CacheDir, cacheDir)
+	}
+
+	t.Run("fetches incrementally", func(t *testing.T) {
+		repo := newTemplateRepo(t)
+		v1 := repo.commit(map[string]string{"example/files/VERSION": "v1\n"})
+		repo.tag("v1.0.0")
+		repo.push()
+
+		cacheDir := t.TempDir()
+		useCache(t, repo.URL(), cacheDir)
+
+		source, err := findFromEnv("example")
+		require.NoError(t, err)
+		require.Equal(t, "v1\n", readVersion(t, source))
+		require.Equal(t, v1, source.Commit)
+
+		v2 := repo.commit(map[string]string{"example/files/VERSION": "v2\n"})
+		repo.branch("release")
+		repo.push()
+
+		source, err = findFromEnv("example")
+		require.NoError(t, err)
+		require.Equal(t, "v2\n", readVersion(t, source))
+		require.Equal(t, v2, source.Commit)
+
+		for _, ref := range []string{"v1.0.0", v1[:7]} {
+			viper.Set(config.Ref, ref)
+			source, err = findFromEnv("example")
+			require.NoError(t, err, ref)
+			require.Equal(t, "v1\n", readVersion(t, source), ref)
+		}
+
+		viper.Set(config.Ref, "")
+		viper.Set(config.BranchName, "release")
+		source, err = findFromEnv("example")
+		require.NoError(t, err)
+		require.Equal(t, v2, source.Commit)
+
+		repos, err := NewRepoCache(zerolog.Nop(), cacheDir).List()
+		require.NoError(t, err)
+		require.Len(t, repos, 1)
+		require.Equal(t, repo.URL(), repos[0].URL)
+	})
+
+	t.Run("offline", func(t *testing.T) {
+		repo := newTemplateRepo(t)
+		v1 := repo.commit(map[string]string{"example/files/VERSION": "v1\n"})
+		repo.push()
+
+		cacheDir := t.TempDir()
+		useCache(t, repo.URL(), cacheDir)
+
+		viper.Set(config.Offline, true)
+		_, err := findFromEnv("example")
+		require.EqualError(t, err, "no cached copy of "+repo.URL()+", run without --offline first")
+
+		viper.Set(config.Offline, false)
+		_, err = findFromEnv("example")
+		require.NoError(t, err)
+
+		// Nothing newer should be seen, and the remote shouldn't be needed at all
+		repo.commit(map[string]string{"example/files/VERSION": "v2\n"})
+		repo.push()
+		require.NoError(t, os.RemoveAll(repo.bare))
+
+		viper.Set(config.Offline, true)
+		source, err := findFromEnv("example")
+		require.NoError(t, err)
+		require.Equal(t, "v1\n", readVersion(t, source))
+		require.Equal(t, v1, source.Commit)
+	})
+
+	t.Run("offline without cache dir", func(t *testing.T) {
+		useCache(t, "file:///nowhere", "")
+		viper.Set(config.Offline, true)
+
+		_, err := findFromEnv("example")
+		require.EqualError(t, err, "offline requires a cache-dir")
+	})
+
+	t.Run("failed fetch is not cached", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		useCache(t, "file://"+t.TempDir()+"/missing", cacheDir)
+
+		_, err := findFromEnv("example")
+		require.ErrorContains(t, err, "error fetching repo")
+
+		repos, err := NewRepoCache(zerolog.Nop(), cacheDir).List()
+		require.NoError(t, err)
+		require.Empty(t, repos)
+	})
+
+	t.Run("clean", func(t *testing.T) {
+		cacheDir := t.TempDir()
+		urls := []string{}
+		for i := 0; i < 2; i++ {
+			repo := newTemplateRepo(t)
+			repo.commit(map[string]string{"example/files/VERSION": "v1\n"})
+			repo.push()
+
+			useCache(t, repo.URL(), cacheDir)
+			_, err := findFromEnv("example")
+			require.NoError(t, err)
+			urls = append(urls, repo.URL())
+		}
+
+		cache := NewRepoCache(zerolog.Nop(), cacheDir)
+		repos, err := cache.List()
+		require.NoError(t, err)
+		require.Len(t, repos, 2)
+
+		_, err = cache.Clean("file:///nowhere")
+		require.EqualError(t, err, "file:///nowhere is not cached")
+
+		removed, err := cache.Clean(urls[0])
+		require.NoError(t, err)
+		require.Len(t, removed, 1)
+		require.Equal(t, urls[0], removed[0].URL)
+
+		repos, err = cache.List()
+		require.NoError(t, err)
+		require.Len(t, repos, 1)
+		require.Equal(t, urls[1], repos[0].URL)
+
+		removed, err = cache.Clean()
+		require.NoError(t, err)
+		require.Len(t, removed, 1)
+
+		repos, err = cache.List()
+		require.NoError(t, err)
+		require.Empty(t, repos)
+	})
+
+	t.Run("list missing dir", func(t *testing.T) {
+		repos, err := NewRepoCache(zerolog.Nop(), t.TempDir()+"/missing").List()
+		require.NoError(t, err)
+		require.Empty(t, repos)
+	})
+}
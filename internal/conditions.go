@@ -0,0 +1,41 @@
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// evaluateConditions renders each condition expression, returning the patterns whose condition is false
+func evaluateConditions(conditions map[string]string, data any, funcMap template.FuncMap) ([]string, error) {
+	excluded := []string{}
+
+	for pattern, expr := range conditions {
+		t, err := template.New(pattern).Funcs(funcMap).Parse(expr)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing condition for %v: %w", pattern, err)
+		}
+
+		var out strings.Builder
+		if err := t.Execute(&out, data); err != nil {
+			return nil, fmt.Errorf("error evaluating condition for %v: %w", pattern, err)
+		}
+
+		if !truthy(out.String()) {
+			excluded = append(excluded, pattern)
+		}
+	}
+	sort.Strings(excluded)
+
+	return excluded, nil
+}
+
+func truthy(val string) bool {
+	switch strings.ToLower(strings.TrimSpace(val)) {
+	case "", "false", "0", "no", "<no value>":
+		return false
+	default:
+		return true
+	}
+}
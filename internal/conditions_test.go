@@ -0,0 +1,100 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvaluateConditions(t *testing.T) {
+	data := map[string]any{
+		"UseDocker": false,
+		"UseCI":     true,
+		"Database":  "postgres",
+	}
+
+	excluded, err := evaluateConditions(
+		map[string]string{
+			"Dockerfile":        "{{ .UseDocker }}",
+			".github/workflows": "{{ .UseCI }}",
+			"migrations":        `{{ eq .Database "mysql" }}`,
+			"sql":               `{{ if .Database }}yes{{ end }}`,
+			"missing":           "{{ .Missing }}",
+		},
+		data,
+		template.FuncMap{},
+	)
+	require.NoError(t, err)
+	require.Equal(t, []string{"Dockerfile", "migrations", "missing"}, excluded)
+
+	t.Run("invalid expression", func(t *testing.T) {
+		_, err := evaluateConditions(map[string]string{"Dockerfile": "{{ .UseDocker "}, data, template.FuncMap{})
+		require.ErrorContains(t, err, "error parsing condition for Dockerfile")
+	})
+}
+
+func TestExecuteConditions(t *testing.T) {
+	inpFS := memfs.New()
+	require.NoError(t, inpFS.MkdirAll("files/.github/workflows", 0775))
+	require.NoError(t, inpFS.WriteFile("files/.github/workflows/ci.yml", []byte("on: push\n"), 0664))
+	require.NoError(t, inpFS.WriteFile("files/Dockerfile", []byte("FROM scratch\n"), 0664))
+	require.NoError(t, inpFS.WriteFile("files/main.go", []byte("package main\n"), 0664))
+	require.NoError(t, inpFS.WriteFile(
+		"config.yaml",
+		[]byte(dedent.Dedent(`
+			not-module: true
+			variables:
+			  - name: UseDocker
+			    type: bool
+			  - name: UseCI
+			    type: bool
+			    default: true
+			conditions:
+			  Dockerfile: "{{ .UseDocker }}"
+			  .github/workflows: "{{ .UseCI }}"
+		`)),
+		0664,
+	))
+
+	t.Run("defaults", func(t *testing.T) {
+		var logs bytes.Buffer
+		dir := t.TempDir()
+
+		sk := NewSkeley(SkeleyConfig{
+			Logger:     zerolog.New(&logs).Level(zerolog.DebugLevel),
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.FileExists(t, filepath.Join(dir, "main.go"))
+		require.FileExists(t, filepath.Join(dir, ".github", "workflows", "ci.yml"))
+		require.NoFileExists(t, filepath.Join(dir, "Dockerfile"))
+		require.Contains(t, logs.String(), `"path":"Dockerfile","message":"condition is false, skipping"`)
+	})
+
+	t.Run("overridden", func(t *testing.T) {
+		dir := t.TempDir()
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+			Values: map[string]any{
+				"UseDocker": "true",
+				"UseCI":     "false",
+			},
+		})
+		require.NoError(t, sk.Execute())
+
+		require.FileExists(t, filepath.Join(dir, "Dockerfile"))
+		_, err := os.Stat(filepath.Join(dir, ".github"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lithammer/dedent"
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOnConflict(t *testing.T) {
+	newInput := func(t *testing.T) *memfs.FS {
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/conflict.txt", []byte("new\n"), 0664))
+		require.NoError(t, inpFS.WriteFile("files/same.txt", []byte("same\n"), 0664))
+		require.NoError(t, inpFS.WriteFile("files/fresh.txt", []byte("fresh\n"), 0664))
+		require.NoError(t, inpFS.WriteFile("config.yaml", []byte("not-module: true\n"), 0664))
+		return inpFS
+	}
+
+	// newOutput creates an output directory with an existing conflicting file and an identical file, with the mtime of
+	// the identical file set in the past
+	newOutput := func(t *testing.T) (string, time.Time) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "conflict.txt"), []byte("old\n"), 0664))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "same.txt"), []byte("same\n"), 0664))
+
+		past := time.Now().Add(-time.Hour).Truncate(time.Second)
+		require.NoError(t, os.Chtimes(filepath.Join(dir, "same.txt"), past, past))
+		return dir, past
+	}
+
+	readFile := func(t *testing.T, dir string, name string) string {
+		t.Helper()
+		content, err := os.ReadFile(filepath.Join(dir, name))
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	requireUntouched := func(t *testing.T, dir string, past time.Time) {
+		t.Helper()
+		info, err := os.Stat(filepath.Join(dir, "same.txt"))
+		require.NoError(t, err)
+		require.True(t, info.ModTime().Equal(past), "identical file should not be rewritten")
+		require.Equal(t, "fresh\n", readFile(t, dir, "fresh.txt"))
+	}
+
+	t.Run("overwrite", func(t *testing.T) {
+		dir, past := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyOverwrite,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "new\n", readFile(t, dir, "conflict.txt"))
+		require.NoFileExists(t, filepath.Join(dir, "conflict.txt.orig"))
+		requireUntouched(t, dir, past)
+	})
+
+	t.Run("default is overwrite", func(t *testing.T) {
+		dir, past := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "new\n", readFile(t, dir, "conflict.txt"))
+		requireUntouched(t, dir, past)
+	})
+
+	t.Run("skip", func(t *testing.T) {
+		dir, past := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicySkip,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "old\n", readFile(t, dir, "conflict.txt"))
+		requireUntouched(t, dir, past)
+	})
+
+	t.Run("error", func(t *testing.T) {
+		dir, _ := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyError,
+		})
+		require.EqualError(t, sk.Execute(), "files already exist in the output directory: conflict.txt")
+
+		require.Equal(t, "old\n", readFile(t, dir, "conflict.txt"))
+		require.NoFileExists(t, filepath.Join(dir, "fresh.txt"))
+	})
+
+	t.Run("error before pre-cmds", func(t *testing.T) {
+		dir, _ := newOutput(t)
+		inpFS := newInput(t)
+		require.NoError(t, inpFS.WriteFile("config.yaml", []byte("not-module: true\npre-cmds:\n  - touch ran\n"), 0664))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyError,
+		})
+		require.EqualError(t, sk.Execute(), "files already exist in the output directory: conflict.txt")
+
+		require.NoFileExists(t, filepath.Join(dir, "ran"))
+	})
+
+	t.Run("pre-cmds create conflicts", func(t *testing.T) {
+		dir := t.TempDir()
+		inpFS := newInput(t)
+		require.NoError(t, inpFS.WriteFile("config.yaml", []byte("not-module: true\npre-cmds:\n  - echo old > conflict.txt\n"), 0664))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyError,
+		})
+		require.EqualError(t, sk.Execute(), "files already exist in the output directory: conflict.txt")
+
+		require.NoFileExists(t, filepath.Join(dir, "fresh.txt"))
+	})
+
+	t.Run("backup", func(t *testing.T) {
+		dir, past := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyBackup,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "new\n", readFile(t, dir, "conflict.txt"))
+		require.Equal(t, "old\n", readFile(t, dir, "conflict.txt.orig"))
+		require.NoFileExists(t, filepath.Join(dir, "same.txt.orig"))
+		requireUntouched(t, dir, past)
+	})
+
+	t.Run("backup keeps earlier backups", func(t *testing.T) {
+		dir, _ := newOutput(t)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "conflict.txt.orig"), []byte("older\n"), 0664))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "conflict.txt.orig.1"), []byte("oldest\n"), 0664))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyBackup,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "new\n", readFile(t, dir, "conflict.txt"))
+		require.Equal(t, "older\n", readFile(t, dir, "conflict.txt.orig"))
+		require.Equal(t, "oldest\n", readFile(t, dir, "conflict.txt.orig.1"))
+		require.Equal(t, "old\n", readFile(t, dir, "conflict.txt.orig.2"))
+	})
+
+	t.Run("prompt yes", func(t *testing.T) {
+		dir, past := newOutput(t)
+		var out bytes.Buffer
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyPrompt,
+			Prompter:   NewPrompter(strings.NewReader("y\n"), &out),
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "new\n", readFile(t, dir, "conflict.txt"))
+		require.Equal(
+			t,
+			dedent.Dedent(`
+				--- conflict.txt
+				+++ conflict.txt (template)
+				@@ -1 +1 @@
+				-old
+				+new
+				Overwrite conflict.txt? [y/N]: `)[1:],
+			out.String(),
+		)
+		requireUntouched(t, dir, past)
+	})
+
+	t.Run("prompt no", func(t *testing.T) {
+		dir, past := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyPrompt,
+			Prompter:   NewPrompter(strings.NewReader("n\n"), &bytes.Buffer{}),
+		})
+		require.NoError(t, sk.Execute())
+
+		require.Equal(t, "old\n", readFile(t, dir, "conflict.txt"))
+		requireUntouched(t, dir, past)
+	})
+
+	t.Run("prompt without terminal", func(t *testing.T) {
+		dir, _ := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyPrompt,
+		})
+		require.EqualError(t, sk.Execute(), "conflict.txt already exists and cannot prompt to overwrite it without a terminal")
+		require.NoFileExists(t, filepath.Join(dir, "fresh.txt"))
+	})
+
+	t.Run("plan reflects policy", func(t *testing.T) {
+		dir, _ := newOutput(t)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			OnConflict: config.ConflictPolicyBackup,
+		})
+		plan, err := sk.Plan()
+		require.NoError(t, err)
+
+		actions := map[string]PlanAction{}
+		for _, f := range plan.Files {
+			actions[f.Path] = f.Action
+		}
+		require.Equal(
+			t,
+			map[string]PlanAction{
+				"conflict.txt": PlanActionBackup,
+				"same.txt":     PlanActionUnchanged,
+				"fresh.txt":    PlanActionCreate,
+			},
+			actions,
+		)
+	})
+}
@@ -0,0 +1,83 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIsBinary(t *testing.T) {
+	testData := []struct {
+		name     string
+		content  []byte
+		expected bool
+	}{
+		{name: "text", content: []byte("package main\n"), expected: false},
+		{name: "empty", content: []byte{}, expected: false},
+		{name: "utf8", content: []byte("héllo wörld"), expected: false},
+		{name: "png", content: []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR"), expected: true},
+		{name: "invalid utf8", content: []byte{0xff, 0xfe, 'a'}, expected: true},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, isBinary(tc.content))
+		})
+	}
+}
+
+func TestExecuteCopyFiles(t *testing.T) {
+	png := []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR{{ .Module }}\xff\x00")
+	script := []byte("#!/bin/sh\necho '{{ not a template'\n")
+	helm := []byte("name: {{ .Values.name }}\n")
+
+	inpFS := memfs.New()
+	require.NoError(t, inpFS.MkdirAll("files/assets", 0775))
+	require.NoError(t, inpFS.MkdirAll("files/chart/templates", 0775))
+	require.NoError(t, inpFS.WriteFile("files/assets/logo.png", png, 0664))
+	require.NoError(t, inpFS.WriteFile("files/gradlew", script, 0775))
+	require.NoError(t, inpFS.WriteFile("files/chart/templates/deployment.yaml", helm, 0664))
+	require.NoError(t, inpFS.WriteFile("files/README.md", []byte("# {{ .Name }}\n"), 0664))
+	require.NoError(t, inpFS.WriteFile(
+		"config.yaml",
+		[]byte(dedent.Dedent(`
+			not-module: true
+			copy-only:
+			  - gradlew
+			raw:
+			  - chart/**
+			variables:
+			  - name: Name
+			    default: svc
+		`)),
+		0664,
+	))
+
+	dir := t.TempDir()
+	sk := NewSkeley(SkeleyConfig{
+		InputFS:    inpFS,
+		OutputPath: dir,
+	})
+	require.NoError(t, sk.Execute())
+
+	for name, expected := range map[string][]byte{
+		"assets/logo.png":                 png,
+		"gradlew":                         script,
+		"chart/templates/deployment.yaml": helm,
+		"README.md":                       []byte("# svc\n"),
+	} {
+		got, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+		require.NoError(t, err)
+		require.Equal(t, expected, got, name)
+	}
+
+	if runtime.GOOS != "windows" {
+		info, err := os.Stat(filepath.Join(dir, "gradlew"))
+		require.NoError(t, err)
+		require.NotZero(t, info.Mode().Perm()&0100, "copied file should stay executable")
+	}
+}
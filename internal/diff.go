@@ -0,0 +1,32 @@
+package internal
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// unifiedDiff renders a unified diff between two versions of a file, or a one line summary if either is binary
+func unifiedDiff(fromName string, toName string, from []byte, to []byte) (string, error) {
+	if isBinary(from) || isBinary(to) {
+		return fmt.Sprintf("Binary files %v and %v differ\n", fromName, toName), nil
+	}
+
+	return difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        splitLines(string(from)),
+		B:        splitLines(string(to)),
+		FromFile: fromName,
+		ToFile:   toName,
+		Context:  3,
+	})
+}
+
+// splitLines splits content into lines that each keep their line ending
+func splitLines(content string) []string {
+	lines := strings.SplitAfter(content, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
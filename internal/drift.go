@@ -0,0 +1,170 @@
+package internal
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"sort"
+
+	"github.com/nicjohnson145/skeley/config"
+)
+
+//go:generate go-enum -f $GOFILE -marshal -names
+
+/*
+ENUM(
+modified
+deleted
+)
+*/
+type DriftStatus string
+
+// DriftedFile is a file from the template that has been changed or deleted in the project
+type DriftedFile struct {
+	Path   string      `json:"path"`
+	Status DriftStatus `json:"status"`
+	// diff is a unified diff from the rendered template to the project's file
+	diff string
+}
+
+// Drift renders the template and compares it against the files already in the output directory, returning the files
+// that differ from what the template produces. Files in the project that the template doesn't produce aren't drift.
+// Secrets aren't recorded in the manifest, so if one has no value the files are compared against the hashes in the
+// manifest instead, which finds the same files without showing how they differ
+func (s *Skeley) Drift() ([]DriftedFile, error) {
+	tmpl, err := s.loadTemplate()
+	if err != nil {
+		return nil, err
+	}
+	if unresolved := s.unresolvedSecrets(tmpl.conf.Variables); len(unresolved) > 0 {
+		s.log.Warn().Strs("variables", unresolved).Msg("secret variables have no value, comparing against the manifest instead of the template")
+		return s.manifestDrift()
+	}
+
+	values, err := s.resolveValues(tmpl.conf.Variables)
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.buildPlan(tmpl, values)
+	if err != nil {
+		return nil, err
+	}
+
+	drifted := []DriftedFile{}
+	for _, f := range plan.Files {
+		var (
+			status  DriftStatus
+			current []byte
+		)
+		switch f.Action {
+		case PlanActionUnchanged:
+			continue
+		case PlanActionCreate:
+			status = DriftStatusDeleted
+		default:
+			status = DriftStatusModified
+			if current, err = os.ReadFile(s.outputFile(f.Path)); err != nil {
+				return nil, err
+			}
+		}
+
+		rendered, err := f.read()
+		if err != nil {
+			return nil, err
+		}
+		toName := f.Path
+		if status == DriftStatusDeleted {
+			toName = "/dev/null"
+		}
+		diff, err := unifiedDiff(f.Path+" (template)", toName, rendered, current)
+		if err != nil {
+			return nil, err
+		}
+
+		drifted = append(drifted, DriftedFile{Path: f.Path, Status: status, diff: diff})
+	}
+
+	return drifted, nil
+}
+
+// unresolvedSecrets returns the secret variables that have no value and can't be prompted for. Their defaults aren't
+// used, as the project may have been generated with any value
+func (s *Skeley) unresolvedSecrets(decls []templateVariable) []string {
+	if s.conf.Prompter != nil {
+		return nil
+	}
+
+	env := envValues(decls)
+	unresolved := []string{}
+	for _, d := range decls {
+		if !d.Secret {
+			continue
+		}
+		if _, ok := s.conf.Values[d.Name]; ok {
+			continue
+		}
+		if _, ok := env[d.Name]; ok {
+			continue
+		}
+		unresolved = append(unresolved, d.Name)
+	}
+	return unresolved
+}
+
+// manifestDrift compares the project's files against the hashes recorded in its manifest
+func (s *Skeley) manifestDrift() ([]DriftedFile, error) {
+	manifest, err := ReadManifest(s.outputPath)
+	if err != nil {
+		return nil, err
+	}
+
+	paths := make([]string, 0, len(manifest.Files))
+	for path := range manifest.Files {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	drifted := []DriftedFile{}
+	for _, path := range paths {
+		f, err := os.Open(s.outputFile(path))
+		if errors.Is(err, fs.ErrNotExist) {
+			drifted = append(drifted, DriftedFile{Path: path, Status: DriftStatusDeleted, diff: fmt.Sprintf("%v was deleted\n", path)})
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		hash, err := hashReader(f)
+		f.Close()
+		if err != nil {
+			return nil, fmt.Errorf("error hashing %v: %w", path, err)
+		}
+		if hash != manifest.Files[path] {
+			drifted = append(drifted, DriftedFile{Path: path, Status: DriftStatusModified, diff: fmt.Sprintf("%v differs from the template\n", path)})
+		}
+	}
+
+	return drifted, nil
+}
+
+// WriteDrift prints the drifted files in the given format, as unified diffs for text
+func WriteDrift(w io.Writer, files []DriftedFile, format config.OutputFormat) error {
+	switch format {
+	case config.OutputFormatJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(files)
+	case config.OutputFormatText:
+		for _, f := range files {
+			if _, err := fmt.Fprint(w, f.diff); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unhandled output format %v", format)
+	}
+}
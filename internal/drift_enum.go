@@ -0,0 +1,73 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version: 0.5.8
+// Revision: 3d844c8ecc59661ed7aa17bfd65727bc06a60ad8
+// Build Date: 2023-09-18T14:55:21Z
+// Built By: goreleaser
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// DriftStatusModified is a DriftStatus of type modified.
+	DriftStatusModified DriftStatus = "modified"
+	// DriftStatusDeleted is a DriftStatus of type deleted.
+	DriftStatusDeleted DriftStatus = "deleted"
+)
+
+var ErrInvalidDriftStatus = fmt.Errorf("not a valid DriftStatus, try [%s]", strings.Join(_DriftStatusNames, ", "))
+
+var _DriftStatusNames = []string{
+	string(DriftStatusModified),
+	string(DriftStatusDeleted),
+}
+
+// DriftStatusNames returns a list of possible string values of DriftStatus.
+func DriftStatusNames() []string {
+	tmp := make([]string, len(_DriftStatusNames))
+	copy(tmp, _DriftStatusNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x DriftStatus) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x DriftStatus) IsValid() bool {
+	_, err := ParseDriftStatus(string(x))
+	return err == nil
+}
+
+var _DriftStatusValue = map[string]DriftStatus{
+	"modified": DriftStatusModified,
+	"deleted":  DriftStatusDeleted,
+}
+
+// ParseDriftStatus attempts to convert a string to a DriftStatus.
+func ParseDriftStatus(name string) (DriftStatus, error) {
+	if x, ok := _DriftStatusValue[name]; ok {
+		return x, nil
+	}
+	return DriftStatus(""), fmt.Errorf("%s is %w", name, ErrInvalidDriftStatus)
+}
+
+// MarshalText implements the text marshaller method.
+func (x DriftStatus) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *DriftStatus) UnmarshalText(text []byte) error {
+	tmp, err := ParseDriftStatus(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
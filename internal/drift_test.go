@@ -0,0 +1,123 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrift(t *testing.T) {
+	src := memfs.New()
+	require.NoError(t, src.MkdirAll("service/files", 0775))
+	require.NoError(t, src.WriteFile("service/config.yaml", []byte("not-module: true\nvariables:\n  - name: Name\n"), 0664))
+	require.NoError(t, src.WriteFile("service/files/README.md", []byte("# {{ .Name }}\nAbout.\n"), 0664))
+	require.NoError(t, src.WriteFile("service/files/Makefile", []byte("build:\n\tgo build\n"), 0664))
+	require.NoError(t, src.WriteFile("service/files/ci.yaml", []byte("lint: true\n"), 0664))
+
+	sk := newTemplateSkeley(t, src, "service")
+	sk.conf.Values = map[string]any{"Name": "billing"}
+	require.NoError(t, sk.Execute())
+	dir := sk.conf.OutputPath
+
+	t.Run("no drift", func(t *testing.T) {
+		drifted, err := sk.Drift()
+		require.NoError(t, err)
+		require.Empty(t, drifted)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# billing\nAbout billing.\n"), 0664))
+	require.NoError(t, os.Remove(filepath.Join(dir, "ci.yaml")))
+	// Files the template doesn't produce aren't drift
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0664))
+
+	drifted, err := sk.Drift()
+	require.NoError(t, err)
+
+	t.Run("text", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, WriteDrift(buf, drifted, config.OutputFormatText))
+		require.Equal(
+			t,
+			dedent.Dedent(`
+				--- README.md (template)
+				+++ README.md
+				@@ -1,2 +1,2 @@
+				 # billing
+				-About.
+				+About billing.
+				--- ci.yaml (template)
+				+++ /dev/null
+				@@ -1 +0,0 @@
+				-lint: true
+			`)[1:],
+			buf.String(),
+		)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		buf := &bytes.Buffer{}
+		require.NoError(t, WriteDrift(buf, drifted, config.OutputFormatJson))
+		require.JSONEq(
+			t,
+			`[{"path": "README.md", "status": "modified"}, {"path": "ci.yaml", "status": "deleted"}]`,
+			buf.String(),
+		)
+	})
+}
+
+func TestDriftSecret(t *testing.T) {
+	src := memfs.New()
+	require.NoError(t, src.MkdirAll("service/files", 0775))
+	require.NoError(t, src.WriteFile("service/config.yaml", []byte(dedent.Dedent(`
+		not-module: true
+		variables:
+		  - name: Token
+		    secret: true
+		    required: true
+	`)), 0664))
+	require.NoError(t, src.WriteFile("service/files/.env", []byte("TOKEN={{ .Token }}\n"), 0664))
+	require.NoError(t, src.WriteFile("service/files/README.md", []byte("# service\n"), 0664))
+
+	sk := newTemplateSkeley(t, src, "service")
+	sk.conf.SourceInfo = SourceInfo{Type: config.SourceTypeLocal, Location: "templates"}
+	sk.conf.Values = map[string]any{"Token": "hunter2"}
+	require.NoError(t, sk.Execute())
+	dir := sk.conf.OutputPath
+
+	t.Run("given value", func(t *testing.T) {
+		drifted, err := sk.Drift()
+		require.NoError(t, err)
+		require.Empty(t, drifted)
+	})
+
+	// As run by diff, the secret isn't in the manifest's answers and can't be prompted for
+	sk.conf.Values = map[string]any{}
+
+	t.Run("no value", func(t *testing.T) {
+		drifted, err := sk.Drift()
+		require.NoError(t, err)
+		require.Empty(t, drifted)
+	})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# billing\n"), 0664))
+	require.NoError(t, os.Remove(filepath.Join(dir, ".env")))
+
+	t.Run("no value drifted", func(t *testing.T) {
+		drifted, err := sk.Drift()
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]DriftedFile{
+				{Path: ".env", Status: DriftStatusDeleted, diff: ".env was deleted\n"},
+				{Path: "README.md", Status: DriftStatusModified, diff: "README.md differs from the template\n"},
+			},
+			drifted,
+		)
+	})
+}
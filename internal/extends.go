@@ -0,0 +1,122 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"strings"
+)
+
+// loadedTemplate is a template with its parents resolved
+type loadedTemplate struct {
+	// conf is the effective config, merged from every template in the chain
+	conf templateConfig
+	// layers are the roots of each template in the chain, starting from the root ancestor and ending with the template
+	// itself. Files in later layers override those in earlier ones
+	layers []fs.FS
+	// chain is the names of the parent templates, nearest first
+	chain []string
+}
+
+// loadTemplate reads the template config, following `extends` through the template source to each parent
+func (s *Skeley) loadTemplate() (loadedTemplate, error) {
+	conf, err := s.getTemplateConfig()
+	if err != nil {
+		return loadedTemplate{}, err
+	}
+
+	loaded := loadedTemplate{
+		conf:   conf,
+		layers: []fs.FS{s.inputFS},
+	}
+
+	seen := []string{}
+	if s.conf.Template != "" {
+		seen = append(seen, s.conf.Template)
+	}
+
+	parent := conf.Extends
+	for parent != "" {
+		for _, name := range seen {
+			if name == parent {
+				return loadedTemplate{}, fmt.Errorf("template inheritance cycle: %v -> %v", strings.Join(seen, " -> "), parent)
+			}
+		}
+		seen = append(seen, parent)
+
+		s.log.Debug().Str("template", parent).Msg("loading parent template")
+		parentFS, err := s.parentFS(parent)
+		if err != nil {
+			return loadedTemplate{}, err
+		}
+
+		parentConf, err := readTemplateConfig(parentFS)
+		if err != nil {
+			return loadedTemplate{}, fmt.Errorf("error reading config for parent template %v: %w", parent, err)
+		}
+
+		loaded.conf = mergeConfig(parentConf, loaded.conf)
+		loaded.layers = append([]fs.FS{parentFS}, loaded.layers...)
+		loaded.chain = append(loaded.chain, parent)
+		parent = parentConf.Extends
+	}
+
+	return loaded, nil
+}
+
+func (s *Skeley) parentFS(name string) (fs.FS, error) {
+	if s.conf.SourceFS == nil {
+		return nil, fmt.Errorf("cannot resolve parent template %v without a template source", name)
+	}
+
+	info, err := fs.Stat(s.conf.SourceFS, name)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("parent template %v not found", name)
+		}
+		return nil, fmt.Errorf("error reading parent template %v: %w", name, err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("parent template %v is not a directory", name)
+	}
+
+	return fs.Sub(s.conf.SourceFS, name)
+}
+
+// mergeConfig combines a parent config with its child. Lists are concatenated parent first, and where both declare
+// the same variable or condition the child wins
+func mergeConfig(parent templateConfig, child templateConfig) templateConfig {
+	merged := child
+	merged.Extends = parent.Extends
+
+	merged.PreCmds = append(append([]string{}, parent.PreCmds...), child.PreCmds...)
+	merged.PostCmds = append(append([]string{}, parent.PostCmds...), child.PostCmds...)
+	merged.CopyOnly = append(append([]string{}, parent.CopyOnly...), child.CopyOnly...)
+	merged.Raw = append(append([]string{}, parent.Raw...), child.Raw...)
+	merged.NotModule = parent.NotModule || child.NotModule
+	merged.EscapeHTML = parent.EscapeHTML || child.EscapeHTML
+
+	merged.Variables = []templateVariable{}
+	overridden := map[string]bool{}
+	for _, v := range child.Variables {
+		overridden[v.Name] = true
+	}
+	for _, v := range parent.Variables {
+		if !overridden[v.Name] {
+			merged.Variables = append(merged.Variables, v)
+		}
+	}
+	merged.Variables = append(merged.Variables, child.Variables...)
+
+	if len(parent.Conditions) > 0 {
+		merged.Conditions = map[string]string{}
+		for k, v := range parent.Conditions {
+			merged.Conditions[k] = v
+		}
+		for k, v := range child.Conditions {
+			merged.Conditions[k] = v
+		}
+	}
+
+	return merged
+}
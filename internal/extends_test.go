@@ -0,0 +1,167 @@
+package internal
+
+import (
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestExtends(t *testing.T) {
+	newSource := func(t *testing.T) *memfs.FS {
+		src := memfs.New()
+
+		// base is the root ancestor
+		require.NoError(t, src.MkdirAll("base/files/cmd", 0775))
+		require.NoError(t, src.MkdirAll("base/partials", 0775))
+		require.NoError(t, src.WriteFile("base/config.yaml", []byte(dedent.Dedent(`
+			not-module: true
+			pre-cmds:
+			  - echo base-pre >> hooks.txt
+			variables:
+			  - name: Service
+			    default: base-svc
+			  - name: Port
+			    type: int
+			    default: 80
+		`)), 0664))
+		require.NoError(t, src.WriteFile("base/partials/header.tmpl", []byte("// base header\n"), 0664))
+		require.NoError(t, src.WriteFile("base/files/Makefile", []byte("build:\n\tgo build\n"), 0664))
+		require.NoError(t, src.WriteFile("base/files/README.md", []byte("# base {{ .Service }}\n"), 0664))
+		require.NoError(t, src.WriteFile("base/files/cmd/main.go", []byte(dedent.Dedent(`
+			{{- template "header" . -}}
+			package main
+
+			{{ block "imports" . }}import "fmt"{{ end }}
+
+			func main() {
+				{{- block "body" . }}
+				fmt.Println("{{ .Service }}:{{ .Port }}")
+				{{- end }}
+			}
+		`)), 0664))
+
+		// service extends base, overriding a variable, a file and a block
+		require.NoError(t, src.MkdirAll("service/files/cmd", 0775))
+		require.NoError(t, src.WriteFile("service/config.yaml", []byte(dedent.Dedent(`
+			extends: base
+			pre-cmds:
+			  - echo service-pre >> hooks.txt
+			variables:
+			  - name: Port
+			    type: int
+			    default: 8080
+		`)), 0664))
+		require.NoError(t, src.WriteFile("service/files/README.md", []byte("# service {{ .Service }}\n"), 0664))
+		require.NoError(t, src.WriteFile(
+			"service/files/cmd/main.go",
+			[]byte(`{{ define "imports" }}import "log"{{ end }}`),
+			0664,
+		))
+
+		// grpc extends service, adding a file and a variable
+		require.NoError(t, src.MkdirAll("grpc/files", 0775))
+		require.NoError(t, src.WriteFile("grpc/config.yaml", []byte(dedent.Dedent(`
+			extends: service
+			pre-cmds:
+			  - echo grpc-pre >> hooks.txt
+			variables:
+			  - name: Proto
+			    default: api.proto
+		`)), 0664))
+		require.NoError(t, src.WriteFile("grpc/files/buf.yaml", []byte("input: {{ .Proto }}\n"), 0664))
+
+		return src
+	}
+
+	newSkeley := func(t *testing.T, src fs.FS, name string, dir string) *Skeley {
+		inpFS, err := fs.Sub(src, name)
+		require.NoError(t, err)
+
+		return NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			SourceFS:   src,
+			Template:   name,
+			OutputPath: dir,
+		})
+	}
+
+	t.Run("chain", func(t *testing.T) {
+		if runtime.GOOS == "windows" {
+			t.Skip("hooks use posix shell commands")
+		}
+
+		dir := t.TempDir()
+		sk := newSkeley(t, newSource(t), "grpc", dir)
+		require.NoError(t, sk.Execute())
+
+		for name, expected := range map[string]string{
+			"Makefile":  "build:\n\tgo build\n",
+			"README.md": "# service base-svc\n",
+			"buf.yaml":  "input: api.proto\n",
+			"hooks.txt": "base-pre\nservice-pre\ngrpc-pre\n",
+			"cmd/main.go": dedent.Dedent(`
+				// base header
+				package main
+
+				import "log"
+
+				func main() {
+					fmt.Println("base-svc:8080")
+				}
+			`)[1:],
+		} {
+			content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name)))
+			require.NoError(t, err)
+			require.Equal(t, expected, string(content), name)
+		}
+	})
+
+	t.Run("merged config", func(t *testing.T) {
+		sk := newSkeley(t, newSource(t), "grpc", t.TempDir())
+
+		tmpl, err := sk.loadTemplate()
+		require.NoError(t, err)
+		require.Len(t, tmpl.layers, 3)
+		require.Equal(t, []string{"service", "base"}, tmpl.chain)
+		require.Equal(
+			t,
+			[]templateVariable{
+				{Name: "Service", Default: "base-svc"},
+				{Name: "Port", Type: VariableTypeInt, Default: 8080},
+				{Name: "Proto", Default: "api.proto"},
+			},
+			tmpl.conf.Variables,
+		)
+		require.True(t, tmpl.conf.NotModule)
+	})
+
+	t.Run("cycle", func(t *testing.T) {
+		src := newSource(t)
+		require.NoError(t, src.WriteFile("base/config.yaml", []byte("extends: grpc\n"), 0664))
+
+		sk := newSkeley(t, src, "grpc", t.TempDir())
+		require.EqualError(t, sk.Execute(), "template inheritance cycle: grpc -> service -> base -> grpc")
+	})
+
+	t.Run("self", func(t *testing.T) {
+		src := newSource(t)
+		require.NoError(t, src.WriteFile("base/config.yaml", []byte("extends: base\n"), 0664))
+
+		sk := newSkeley(t, src, "base", t.TempDir())
+		require.EqualError(t, sk.Execute(), "template inheritance cycle: base -> base")
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		src := newSource(t)
+		require.NoError(t, src.WriteFile("base/config.yaml", []byte("extends: nope\n"), 0664))
+
+		sk := newSkeley(t, src, "base", t.TempDir())
+		require.EqualError(t, sk.Execute(), "parent template nope not found")
+	})
+}
@@ -0,0 +1,270 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/go-git/go-billy/v5/osfs"
+	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+	"github.com/rs/zerolog"
+	"golang.org/x/mod/modfile"
+	"gopkg.in/yaml.v3"
+)
+
+// extractSkip are never copied into an extracted template. `go.mod` and `go.sum` belong to the project being
+// rendered into, and are restored by the starter config's `go mod tidy`
+var extractSkip = []string{".git", "go.mod", "go.sum"}
+
+// ExtractTemplate creates a template at templateDir from the Go module in projectDir. The module path and binary name
+// are replaced with their template variables, existing template delimiters are escaped, and anything ignored by
+// `.gitignore` is left out. The template is built in a temporary directory beside templateDir and renamed into place,
+// so a failure doesn't leave a partial template behind
+func ExtractTemplate(logger zerolog.Logger, projectDir string, templateDir string) (err error) {
+	mod, err := readModule(filepath.Join(projectDir, "go.mod"))
+	if err != nil {
+		return err
+	}
+
+	if _, err := os.Stat(templateDir); err == nil {
+		return fmt.Errorf("%v already exists", templateDir)
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("error reading %v: %w", templateDir, err)
+	}
+
+	patterns, err := gitignore.ReadPatterns(osfs.New(projectDir), nil)
+	if err != nil {
+		return fmt.Errorf("error reading .gitignore: %w", err)
+	}
+	ignored := gitignore.NewMatcher(patterns)
+
+	if err := os.MkdirAll(filepath.Dir(templateDir), 0775); err != nil {
+		return err
+	}
+	tmpDir, err := os.MkdirTemp(filepath.Dir(templateDir), "."+filepath.Base(templateDir)+"-*")
+	if err != nil {
+		return fmt.Errorf("error creating temporary directory: %w", err)
+	}
+	defer func() {
+		if err != nil {
+			os.RemoveAll(tmpDir)
+		}
+	}()
+
+	filesDir := filepath.Join(tmpDir, "files")
+	count := 0
+	err = fs.WalkDir(os.DirFS(projectDir), ".", func(name string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if name == "." {
+			return nil
+		}
+
+		if contains(extractSkip, name) || ignored.Match(strings.Split(name, "/"), d.IsDir()) {
+			logger.Debug().Str("path", name).Msg("skipping")
+			if d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() {
+			return nil
+		}
+		if !d.Type().IsRegular() {
+			logger.Warn().Str("path", name).Msg("skipping file that isn't a regular file")
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return err
+		}
+		content, err := os.ReadFile(filepath.Join(projectDir, filepath.FromSlash(name)))
+		if err != nil {
+			return fmt.Errorf("error reading %v: %w", name, err)
+		}
+		if !isBinary(content) {
+			content = []byte(templatize(string(content), mod, false))
+		}
+
+		dest := filepath.Join(filesDir, filepath.FromSlash(templatizePath(name, mod)))
+		if err := os.MkdirAll(filepath.Dir(dest), 0775); err != nil {
+			return err
+		}
+		if err := os.WriteFile(dest, content, info.Mode().Perm()); err != nil {
+			return fmt.Errorf("error writing %v: %w", dest, err)
+		}
+
+		count++
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	conf, err := yaml.Marshal(templateConfig{
+		Name:        path.Base(filepath.ToSlash(templateDir)),
+		Description: fmt.Sprintf("Extracted from %v", mod.Module),
+		PostCmds:    []string{"go mod tidy"},
+	})
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(filepath.Join(tmpDir, "config.yaml"), conf, 0664); err != nil {
+		return fmt.Errorf("error writing config: %w", err)
+	}
+
+	// MkdirTemp only gives the owner access
+	if err := os.Chmod(tmpDir, 0775); err != nil {
+		return err
+	}
+	if err := os.Rename(tmpDir, templateDir); err != nil {
+		return fmt.Errorf("error moving template into place: %w", err)
+	}
+
+	logger.Info().Str("module", mod.Module).Int("files", count).Str("template", templateDir).Msg("extracted template")
+	return nil
+}
+
+func readModule(path string) (moduleInfo, error) {
+	modBytes, err := os.ReadFile(path)
+	if err != nil {
+		return moduleInfo{}, fmt.Errorf("error reading go.mod: %w", err)
+	}
+
+	fl, err := modfile.ParseLax(path, modBytes, nil)
+	if err != nil {
+		return moduleInfo{}, fmt.Errorf("error parsing go.mod: %w", err)
+	}
+	if fl.Module == nil {
+		return moduleInfo{}, fmt.Errorf("%v has no module directive", path)
+	}
+
+	return newModuleInfo(fl), nil
+}
+
+// templatize turns file content into a template that renders back to it. Runs of braces containing delimiters are
+// escaped, and the module path is replaced with its variable where it appears as a whole word. Binary names are often
+// ordinary words, like api or server, so in file content the binary name is only replaced as a path segment, next to a
+// slash, or as a quoted string. In a path, where each segment is templatized on its own, any whole word is replaced
+func templatize(content string, mod moduleInfo, path bool) string {
+	type segment struct {
+		text   string
+		action bool
+	}
+	segments := []segment{}
+	literal := func(text string) {
+		if n := len(segments); n > 0 && !segments[n-1].action {
+			segments[n-1].text += text
+			return
+		}
+		segments = append(segments, segment{text: text})
+	}
+
+	names := []struct {
+		value  string
+		action string
+		// anywhere allows replacing whole words outside of paths and quotes
+		anywhere bool
+	}{
+		{value: mod.Module, action: "{{ .Module }}", anywhere: true},
+		{value: mod.BinaryName, action: "{{ .BinaryName }}", anywhere: path},
+	}
+
+	for i := 0; i < len(content); {
+		if content[i] == '{' || content[i] == '}' {
+			end := i
+			for end < len(content) && (content[end] == '{' || content[end] == '}') {
+				end++
+			}
+			run := content[i:end]
+			if strings.Contains(run, "{{") || strings.Contains(run, "}}") {
+				segments = append(segments, segment{text: fmt.Sprintf("{{ %q }}", run), action: true})
+			} else {
+				literal(run)
+			}
+			i = end
+			continue
+		}
+
+		replaced := false
+		for _, n := range names {
+			if n.value == "" || !strings.HasPrefix(content[i:], n.value) {
+				continue
+			}
+			before, after := content[:i], content[i+len(n.value):]
+			if !wordBoundary(before, true) || !wordBoundary(after, false) {
+				continue
+			}
+			if !n.anywhere && !pathSegment(before, after) && !quoted(before, after) {
+				continue
+			}
+
+			segments = append(segments, segment{text: n.action, action: true})
+			i += len(n.value)
+			replaced = true
+			break
+		}
+		if !replaced {
+			literal(content[i : i+1])
+			i++
+		}
+	}
+
+	var out strings.Builder
+	for i, seg := range segments {
+		// A brace right before an action would be read as part of its opening delimiter
+		if !seg.action && strings.HasSuffix(seg.text, "{") && i+1 < len(segments) {
+			out.WriteString(strings.TrimSuffix(seg.text, "{"))
+			out.WriteString(`{{ "{" }}`)
+			continue
+		}
+		out.WriteString(seg.text)
+	}
+
+	return out.String()
+}
+
+// templatizePath templatizes each segment of a slash separated path
+func templatizePath(name string, mod moduleInfo) string {
+	parts := strings.Split(name, "/")
+	for i, p := range parts {
+		parts[i] = templatize(p, mod, true)
+	}
+	return strings.Join(parts, "/")
+}
+
+// wordBoundary reports if the text before (or after) a match doesn't continue the word
+func wordBoundary(s string, before bool) bool {
+	var r rune
+	if before {
+		r, _ = utf8.DecodeLastRuneInString(s)
+	} else {
+		r, _ = utf8.DecodeRuneInString(s)
+	}
+	if r == utf8.RuneError {
+		return true
+	}
+	return !(unicode.IsLetter(r) || unicode.IsDigit(r) || r == '_')
+}
+
+// pathSegment reports if a match is part of a slash separated path
+func pathSegment(before string, after string) bool {
+	return strings.HasSuffix(before, "/") || strings.HasPrefix(after, "/")
+}
+
+// quoted reports if a match is the whole of a quoted string
+func quoted(before string, after string) bool {
+	if before == "" || after == "" {
+		return false
+	}
+	q := before[len(before)-1]
+	return (q == '"' || q == '\'' || q == '`') && after[0] == q
+}
@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplatize(t *testing.T) {
+	mod := moduleInfo{Module: "github.com/acme/widget", BinaryName: "widget"}
+
+	testData := []struct {
+		name     string
+		content  string
+		expected string
+	}{
+		{
+			name:     "import path",
+			content:  `import "github.com/acme/widget/internal"`,
+			expected: `import "{{ .Module }}/internal"`,
+		},
+		{
+			name:     "binary name",
+			content:  "go build -o bin/widget ./cmd/widget",
+			expected: "go build -o bin/{{ .BinaryName }} ./cmd/{{ .BinaryName }}",
+		},
+		{
+			name:     "binary name quoted",
+			content:  `name := "widget"; flag := 'widget'`,
+			expected: `name := "{{ .BinaryName }}"; flag := '{{ .BinaryName }}'`,
+		},
+		{
+			name:     "binary name as a word",
+			content:  "func widget() { widget := newWidget() }",
+			expected: "func widget() { widget := newWidget() }",
+		},
+		{
+			name:     "only whole words",
+			content:  "bin/widgets ./widget_test mywidget/ \"widget2\" widget-cli/",
+			expected: "bin/widgets ./widget_test mywidget/ \"widget2\" widget-cli/",
+		},
+		{
+			name:     "longer module",
+			content:  "github.com/acme/widgetry",
+			expected: "github.com/acme/widgetry",
+		},
+		{
+			name:     "brace runs",
+			content:  "[]string{}} map[string]any{{{ x }}}",
+			expected: `[]string{{ "{}}" }} map[string]any{{ "{{{" }} x {{ "}}}" }}`,
+		},
+		{
+			name:     "brace before name",
+			content:  "{github.com/acme/widget}",
+			expected: `{{ "{" }}{{ .Module }}}`,
+		},
+		{
+			name:     "delimiters",
+			content:  `{{ .Values.widget }} {{ "widget" }}`,
+			expected: `{{ "{{" }} .Values.widget {{ "}}" }} {{ "{{" }} "{{ .BinaryName }}" {{ "}}" }}`,
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			require.Equal(t, tc.expected, templatize(tc.content, mod, false))
+		})
+	}
+}
+
+func TestExtractTemplate(t *testing.T) {
+	newProject := func(t *testing.T) string {
+		dir := t.TempDir()
+		for name, content := range map[string]string{
+			"go.mod":                "module github.com/acme/widget\n\ngo 1.20\n",
+			"go.sum":                "",
+			".gitignore":            "bin/\nvendor/\n*.log\n",
+			"Makefile":              "build:\n\tgo build -o bin/widget ./cmd/widget\n",
+			"cmd/widget/main.go":    "package main\n\nimport \"github.com/acme/widget/internal\"\n\nfunc main() { internal.Run() }\n",
+			"internal/run.go":       "package internal\n\nfunc Run() {}\n",
+			"deploy/chart.yaml":     "image: {{ .Values.image }}\n",
+			"bin/widget":            "\x00binary",
+			"vendor/modules.txt":    "# vendored\n",
+			"debug.log":             "noise\n",
+			"internal/logo.png":     "\x89PNG\x00widget",
+			"internal/sub/.gitkeep": "",
+		} {
+			path := filepath.Join(dir, filepath.FromSlash(name))
+			require.NoError(t, os.MkdirAll(filepath.Dir(path), 0775))
+			require.NoError(t, os.WriteFile(path, []byte(content), 0664))
+		}
+		return dir
+	}
+
+	t.Run("extract", func(t *testing.T) {
+		project := newProject(t)
+		dest := filepath.Join(t.TempDir(), "widget-service")
+		require.NoError(t, ExtractTemplate(zerolog.Nop(), project, dest))
+
+		for name, expected := range map[string]string{
+			"config.yaml": dedent.Dedent(`
+				name: widget-service
+				description: Extracted from github.com/acme/widget
+				post-cmds:
+				    - go mod tidy
+				not-module: false
+			`)[1:],
+			"files/.gitignore":                    "bin/\nvendor/\n*.log\n",
+			"files/Makefile":                      "build:\n\tgo build -o bin/{{ .BinaryName }} ./cmd/{{ .BinaryName }}\n",
+			"files/cmd/{{ .BinaryName }}/main.go": "package main\n\nimport \"{{ .Module }}/internal\"\n\nfunc main() { internal.Run() }\n",
+			"files/internal/run.go":               "package internal\n\nfunc Run() {}\n",
+			"files/deploy/chart.yaml":             "image: {{ \"{{\" }} .Values.image {{ \"}}\" }}\n",
+			"files/internal/logo.png":             "\x89PNG\x00widget",
+			"files/internal/sub/.gitkeep":         "",
+		} {
+			content, err := os.ReadFile(filepath.Join(dest, filepath.FromSlash(name)))
+			require.NoError(t, err, name)
+			require.Equal(t, expected, string(content), name)
+		}
+
+		for _, name := range []string{"go.mod", "go.sum", "bin", "vendor", "debug.log"} {
+			require.NoFileExists(t, filepath.Join(dest, "files", name))
+			require.NoDirExists(t, filepath.Join(dest, "files", name))
+		}
+	})
+
+	t.Run("round trip", func(t *testing.T) {
+		project := newProject(t)
+		dest := filepath.Join(t.TempDir(), "widget")
+		require.NoError(t, ExtractTemplate(zerolog.Nop(), project, dest))
+		// Don't run go mod tidy
+		require.NoError(t, os.WriteFile(filepath.Join(dest, "config.yaml"), []byte("name: widget\n"), 0664))
+
+		out := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(out, "go.mod"), []byte("module github.com/other/gadget\n\ngo 1.20\n"), 0664))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    os.DirFS(dest),
+			OutputPath: out,
+		})
+		require.NoError(t, sk.Execute())
+
+		for name, expected := range map[string]string{
+			"Makefile":           "build:\n\tgo build -o bin/gadget ./cmd/gadget\n",
+			"cmd/gadget/main.go": "package main\n\nimport \"github.com/other/gadget/internal\"\n\nfunc main() { internal.Run() }\n",
+			"deploy/chart.yaml":  "image: {{ .Values.image }}\n",
+		} {
+			content, err := os.ReadFile(filepath.Join(out, filepath.FromSlash(name)))
+			require.NoError(t, err, name)
+			require.Equal(t, expected, string(content), name)
+		}
+	})
+
+	t.Run("failure leaves nothing behind", func(t *testing.T) {
+		project := newProject(t)
+		// Walked after the other files, and too long a name once each widget is replaced
+		long := strings.TrimSuffix(strings.Repeat("widget-", 36), "-")
+		require.NoError(t, os.MkdirAll(filepath.Join(project, "zz"), 0775))
+		require.NoError(t, os.WriteFile(filepath.Join(project, "zz", long), []byte(""), 0664))
+		parent := t.TempDir()
+		require.Error(t, ExtractTemplate(zerolog.Nop(), project, filepath.Join(parent, "widget")))
+
+		entries, err := os.ReadDir(parent)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("existing template", func(t *testing.T) {
+		dest := t.TempDir()
+		require.EqualError(t, ExtractTemplate(zerolog.Nop(), newProject(t), dest), dest+" already exists")
+	})
+
+	t.Run("not a module", func(t *testing.T) {
+		err := ExtractTemplate(zerolog.Nop(), t.TempDir(), filepath.Join(t.TempDir(), "x"))
+		require.ErrorContains(t, err, "error reading go.mod")
+	})
+}
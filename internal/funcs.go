@@ -0,0 +1,251 @@
+package internal
+
+import (
+	"encoding/json"
+	"fmt"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode"
+
+	"gopkg.in/yaml.v3"
+)
+
+// templateFuncs is the function library available to every template. Functions that take a value to operate on take
+// it as their last argument, so they can be used in pipelines like `{{ .BinaryName | pascal }}`
+func templateFuncs() template.FuncMap {
+	return template.FuncMap{
+		// Case conversion
+		"camel":     camelCase,
+		"pascal":    pascalCase,
+		"snake":     snakeCase,
+		"kebab":     kebabCase,
+		"screaming": screamingCase,
+
+		// Strings
+		"replace":   replace,
+		"trim":      strings.TrimSpace,
+		"split":     split,
+		"join":      join,
+		"pluralize": pluralize,
+		"indent":    indent,
+		"nindent":   nindent,
+		"quote":     quote,
+
+		// Paths
+		"base": path.Base,
+		"dir":  path.Dir,
+		"ext":  path.Ext,
+
+		// Data
+		"default":  defaultValue,
+		"coalesce": coalesce,
+		"ternary":  ternary,
+		"toJson":   toJSON,
+		"toYaml":   toYAML,
+	}
+}
+
+// splitWords breaks an identifier into words on separators, lower to upper case changes and the end of acronyms, so
+// `HTTPServer_name` becomes `HTTP`, `Server` and `name`
+func splitWords(s string) []string {
+	words := []string{}
+	runes := []rune(s)
+	start := -1
+
+	for i, r := range runes {
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			if start != -1 {
+				words = append(words, string(runes[start:i]))
+				start = -1
+			}
+			continue
+		}
+
+		if start == -1 {
+			start = i
+			continue
+		}
+
+		prev := runes[i-1]
+		boundary := false
+		switch {
+		case unicode.IsUpper(r) && (unicode.IsLower(prev) || unicode.IsDigit(prev)):
+			// fooBar, v2Api
+			boundary = true
+		case unicode.IsUpper(r) && unicode.IsUpper(prev) && i+1 < len(runes) && unicode.IsLower(runes[i+1]):
+			// HTTPServer
+			boundary = true
+		}
+		if boundary {
+			words = append(words, string(runes[start:i]))
+			start = i
+		}
+	}
+	if start != -1 {
+		words = append(words, string(runes[start:]))
+	}
+
+	return words
+}
+
+func title(word string) string {
+	runes := []rune(strings.ToLower(word))
+	runes[0] = unicode.ToUpper(runes[0])
+	return string(runes)
+}
+
+// camelCase converts `foo_bar` to `fooBar`
+func camelCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		if i == 0 {
+			words[i] = strings.ToLower(w)
+		} else {
+			words[i] = title(w)
+		}
+	}
+	return strings.Join(words, "")
+}
+
+// pascalCase converts `foo_bar` to `FooBar`
+func pascalCase(s string) string {
+	words := splitWords(s)
+	for i, w := range words {
+		words[i] = title(w)
+	}
+	return strings.Join(words, "")
+}
+
+// snakeCase converts `FooBar` to `foo_bar`
+func snakeCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "_"))
+}
+
+// kebabCase converts `FooBar` to `foo-bar`
+func kebabCase(s string) string {
+	return strings.ToLower(strings.Join(splitWords(s), "-"))
+}
+
+// screamingCase converts `FooBar` to `FOO_BAR`
+func screamingCase(s string) string {
+	return strings.ToUpper(strings.Join(splitWords(s), "_"))
+}
+
+// replace replaces every occurrence of old with new in s
+func replace(old string, new string, s string) string {
+	return strings.ReplaceAll(s, old, new)
+}
+
+// split splits s on sep
+func split(sep string, s string) []string {
+	return strings.Split(s, sep)
+}
+
+// join joins the items of a list with sep
+func join(sep string, list any) (string, error) {
+	val := reflect.ValueOf(list)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return "", fmt.Errorf("join: cannot join %T", list)
+	}
+
+	items := make([]string, 0, val.Len())
+	for i := 0; i < val.Len(); i++ {
+		items = append(items, fmt.Sprint(val.Index(i).Interface()))
+	}
+	return strings.Join(items, sep), nil
+}
+
+// pluralize applies the common English plural rules to a singular noun, `service` to `services`, `policy` to
+// `policies` and `box` to `boxes`
+func pluralize(word string) string {
+	lower := strings.ToLower(word)
+	switch {
+	case word == "":
+		return word
+	case strings.HasSuffix(lower, "s"), strings.HasSuffix(lower, "x"), strings.HasSuffix(lower, "z"),
+		strings.HasSuffix(lower, "ch"), strings.HasSuffix(lower, "sh"):
+		return word + "es"
+	case strings.HasSuffix(lower, "y") && len(lower) > 1 && !strings.ContainsRune("aeiou", rune(lower[len(lower)-2])):
+		return word[:len(word)-1] + "ies"
+	default:
+		return word + "s"
+	}
+}
+
+// indent prefixes every line of s with n spaces
+func indent(n int, s string) string {
+	pad := strings.Repeat(" ", n)
+	return pad + strings.ReplaceAll(s, "\n", "\n"+pad)
+}
+
+// nindent is indent with a leading newline, for use at the end of a line
+func nindent(n int, s string) string {
+	return "\n" + indent(n, s)
+}
+
+// quote wraps a value in double quotes, escaping it as a Go string
+func quote(val any) string {
+	return strconv.Quote(fmt.Sprint(val))
+}
+
+// isEmpty reports if a value is nil or the zero value for its type, or an empty slice or map
+func isEmpty(val any) bool {
+	if val == nil {
+		return true
+	}
+
+	v := reflect.ValueOf(val)
+	switch v.Kind() {
+	case reflect.Slice, reflect.Map, reflect.Array, reflect.String:
+		return v.Len() == 0
+	default:
+		return v.IsZero()
+	}
+}
+
+// defaultValue returns val, or def if val is empty
+func defaultValue(def any, val any) any {
+	if isEmpty(val) {
+		return def
+	}
+	return val
+}
+
+// coalesce returns the first non empty value
+func coalesce(vals ...any) any {
+	for _, v := range vals {
+		if !isEmpty(v) {
+			return v
+		}
+	}
+	return nil
+}
+
+// ternary returns ifTrue if cond is true, otherwise ifFalse
+func ternary(ifTrue any, ifFalse any, cond bool) any {
+	if cond {
+		return ifTrue
+	}
+	return ifFalse
+}
+
+// toJSON encodes a value as compact JSON
+func toJSON(val any) (string, error) {
+	b, err := json.Marshal(val)
+	if err != nil {
+		return "", fmt.Errorf("toJson: %w", err)
+	}
+	return string(b), nil
+}
+
+// toYAML encodes a value as YAML, without a trailing newline
+func toYAML(val any) (string, error) {
+	b, err := yaml.Marshal(val)
+	if err != nil {
+		return "", fmt.Errorf("toYaml: %w", err)
+	}
+	return strings.TrimSuffix(string(b), "\n"), nil
+}
@@ -0,0 +1,110 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateFuncs(t *testing.T) {
+	data := map[string]any{
+		"Name":    "billing-service",
+		"Empty":   "",
+		"Port":    8080,
+		"Enabled": true,
+		"Owners":  []string{"alice", "bob"},
+		"Path":    "cmd/server/main.go",
+		"Map":     map[string]any{"a": 1, "b": []string{"x"}},
+		"Block":   "line1\nline2",
+	}
+
+	testData := []struct {
+		name     string
+		tmpl     string
+		expected string
+	}{
+		// Case conversion
+		{name: "camel", tmpl: `{{ camel .Name }}`, expected: "billingService"},
+		{name: "camel from pascal", tmpl: `{{ camel "HTTPServer" }}`, expected: "httpServer"},
+		{name: "pascal", tmpl: `{{ .Name | pascal }}`, expected: "BillingService"},
+		{name: "pascal from snake", tmpl: `{{ pascal "user_id" }}`, expected: "UserId"},
+		{name: "pascal with digits", tmpl: `{{ pascal "api-v2-client" }}`, expected: "ApiV2Client"},
+		{name: "snake", tmpl: `{{ snake "BillingService" }}`, expected: "billing_service"},
+		{name: "snake acronym", tmpl: `{{ snake "HTTPServerID" }}`, expected: "http_server_id"},
+		{name: "kebab", tmpl: `{{ kebab "billingService" }}`, expected: "billing-service"},
+		{name: "kebab with spaces", tmpl: `{{ kebab "Billing  Service" }}`, expected: "billing-service"},
+		{name: "screaming", tmpl: `{{ screaming .Name }}`, expected: "BILLING_SERVICE"},
+
+		// Strings
+		{name: "replace", tmpl: `{{ .Name | replace "-" "." }}`, expected: "billing.service"},
+		{name: "trim", tmpl: `{{ trim "  padded  " }}`, expected: "padded"},
+		{name: "split", tmpl: `{{ index (split "-" .Name) 1 }}`, expected: "service"},
+		{name: "join", tmpl: `{{ join ", " .Owners }}`, expected: "alice, bob"},
+		{name: "split join", tmpl: `{{ .Name | split "-" | join "_" }}`, expected: "billing_service"},
+		{name: "pluralize", tmpl: `{{ pluralize "service" }}`, expected: "services"},
+		{name: "pluralize y", tmpl: `{{ pluralize "policy" }}`, expected: "policies"},
+		{name: "pluralize vowel y", tmpl: `{{ pluralize "key" }}`, expected: "keys"},
+		{name: "pluralize es", tmpl: `{{ pluralize "box" }} {{ pluralize "match" }} {{ pluralize "status" }}`, expected: "boxes matches statuses"},
+		{name: "indent", tmpl: `{{ indent 2 .Block }}`, expected: "  line1\n  line2"},
+		{name: "nindent", tmpl: `key:{{ .Block | nindent 4 }}`, expected: "key:\n    line1\n    line2"},
+		{name: "quote", tmpl: `{{ quote .Name }} {{ quote .Port }}`, expected: `"billing-service" "8080"`},
+		{name: "quote escapes", tmpl: `{{ quote "say \"hi\"" }}`, expected: `"say \"hi\""`},
+
+		// Paths
+		{name: "base", tmpl: `{{ base .Path }}`, expected: "main.go"},
+		{name: "dir", tmpl: `{{ dir .Path }}`, expected: "cmd/server"},
+		{name: "ext", tmpl: `{{ ext .Path }}`, expected: ".go"},
+
+		// Data
+		{name: "default used", tmpl: `{{ .Empty | default "fallback" }}`, expected: "fallback"},
+		{name: "default missing", tmpl: `{{ .Missing | default "fallback" }}`, expected: "fallback"},
+		{name: "default not used", tmpl: `{{ .Name | default "fallback" }}`, expected: "billing-service"},
+		{name: "default zero int", tmpl: `{{ 0 | default 80 }}`, expected: "80"},
+		{name: "coalesce", tmpl: `{{ coalesce .Empty .Missing .Name "last" }}`, expected: "billing-service"},
+		{name: "ternary true", tmpl: `{{ ternary "yes" "no" .Enabled }}`, expected: "yes"},
+		{name: "ternary pipeline", tmpl: `{{ eq .Port 80 | ternary "http" "custom" }}`, expected: "custom"},
+		{name: "toJson", tmpl: `{{ toJson .Owners }}`, expected: `["alice","bob"]`},
+		{name: "toJson map", tmpl: `{{ toJson .Map }}`, expected: `{"a":1,"b":["x"]}`},
+		{name: "toYaml", tmpl: `{{ toYaml .Owners }}`, expected: "- alice\n- bob"},
+		{name: "toYaml nindent", tmpl: `owners:{{ toYaml .Owners | nindent 2 }}`, expected: "owners:\n  - alice\n  - bob"},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			tmpl, err := template.New(tc.name).Funcs(templateFuncs()).Parse(tc.tmpl)
+			require.NoError(t, err)
+
+			var out strings.Builder
+			require.NoError(t, tmpl.Execute(&out, data))
+			require.Equal(t, tc.expected, out.String())
+		})
+	}
+
+	t.Run("join non list", func(t *testing.T) {
+		tmpl, err := template.New("join").Funcs(templateFuncs()).Parse(`{{ join "," .Port }}`)
+		require.NoError(t, err)
+		require.ErrorContains(t, tmpl.Execute(&strings.Builder{}, data), "join: cannot join int")
+	})
+}
+
+func TestSplitWords(t *testing.T) {
+	testData := []struct {
+		input    string
+		expected []string
+	}{
+		{input: "foo", expected: []string{"foo"}},
+		{input: "fooBar", expected: []string{"foo", "Bar"}},
+		{input: "FooBar", expected: []string{"Foo", "Bar"}},
+		{input: "foo_bar-baz qux", expected: []string{"foo", "bar", "baz", "qux"}},
+		{input: "HTTPServer", expected: []string{"HTTP", "Server"}},
+		{input: "serverHTTP", expected: []string{"server", "HTTP"}},
+		{input: "v2Api", expected: []string{"v2", "Api"}},
+		{input: "__", expected: []string{}},
+	}
+	for _, tc := range testData {
+		t.Run(tc.input, func(t *testing.T) {
+			require.Equal(t, tc.expected, splitWords(tc.input))
+		})
+	}
+}
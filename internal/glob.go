@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// matchGlob reports if a slash separated path, or any directory containing it, matches the pattern. Patterns use
+// path.Match syntax per segment, with `**` matching any number of segments. Patterns without a slash match a name at
+// any depth, so `*.png` matches `assets/logo.png`
+func matchGlob(pattern string, name string) (bool, error) {
+	pattern = strings.Trim(pattern, "/")
+	if !strings.Contains(pattern, "/") && pattern != "**" {
+		pattern = "**/" + pattern
+	}
+
+	patSegs := strings.Split(pattern, "/")
+	nameSegs := strings.Split(name, "/")
+
+	// Match against the path itself and then each of its parent directories
+	for i := len(nameSegs); i > 0; i-- {
+		ok, err := matchSegments(patSegs, nameSegs[:i])
+		if err != nil {
+			return false, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		if ok {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// matchAnyGlob reports if the path matches any of the patterns
+func matchAnyGlob(patterns []string, name string) (bool, error) {
+	for _, p := range patterns {
+		ok, err := matchGlob(p, name)
+		if err != nil || ok {
+			return ok, err
+		}
+	}
+	return false, nil
+}
+
+func matchSegments(pattern []string, name []string) (bool, error) {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// Try consuming every possible number of segments
+			for i := 0; i <= len(name); i++ {
+				ok, err := matchSegments(pattern[1:], name[i:])
+				if err != nil || ok {
+					return ok, err
+				}
+			}
+			return false, nil
+		}
+
+		if len(name) == 0 {
+			return false, nil
+		}
+		ok, err := path.Match(pattern[0], name[0])
+		if err != nil || !ok {
+			return false, err
+		}
+
+		pattern = pattern[1:]
+		name = name[1:]
+	}
+
+	return len(name) == 0, nil
+}
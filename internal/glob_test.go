@@ -0,0 +1,43 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMatchGlob(t *testing.T) {
+	testData := []struct {
+		pattern  string
+		name     string
+		expected bool
+	}{
+		{pattern: "*.png", name: "logo.png", expected: true},
+		{pattern: "*.png", name: "assets/img/logo.png", expected: true},
+		{pattern: "*.png", name: "logo.png.tmpl", expected: false},
+		{pattern: "assets/*.png", name: "assets/logo.png", expected: true},
+		{pattern: "assets/*.png", name: "assets/img/logo.png", expected: false},
+		{pattern: "assets/**/*.png", name: "assets/logo.png", expected: true},
+		{pattern: "assets/**/*.png", name: "assets/img/logo.png", expected: true},
+		{pattern: "assets", name: "assets/img/logo.png", expected: true},
+		{pattern: "assets/", name: "assets/img/logo.png", expected: true},
+		{pattern: ".github/workflows", name: ".github/workflows/ci.yml", expected: true},
+		{pattern: ".github/workflows", name: ".github/dependabot.yml", expected: false},
+		{pattern: "Dockerfile", name: "Dockerfile", expected: true},
+		{pattern: "Dockerfile", name: "build/Dockerfile", expected: true},
+		{pattern: "**", name: "any/thing", expected: true},
+		{pattern: "gradle/wrapper/*.jar", name: "gradle/wrapper/gradle-wrapper.jar", expected: true},
+	}
+	for _, tc := range testData {
+		t.Run(tc.pattern+" "+tc.name, func(t *testing.T) {
+			got, err := matchGlob(tc.pattern, tc.name)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+
+	t.Run("invalid pattern", func(t *testing.T) {
+		_, err := matchGlob("[", "foo")
+		require.Error(t, err)
+	})
+}
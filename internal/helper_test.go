@@ -1,9 +1,18 @@
 package internal
 
 import (
+	"errors"
 	"io/fs"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
 
+	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
+	"github.com/go-git/go-git/v5/plumbing"
+	"github.com/go-git/go-git/v5/plumbing/object"
+	"github.com/rs/zerolog"
 	"github.com/stretchr/testify/require"
 )
 
@@ -30,3 +39,138 @@ func fsEqual(t *testing.T, left fs.FS, right fs.FS) {
 		return nil
 	}))
 }
+
+// templateRepo is a git repository of templates, served from a local bare repository
+type templateRepo struct {
+	t    *testing.T
+	work string
+	repo *git.Repository
+	bare string
+}
+
+func newTemplateRepo(t *testing.T) *templateRepo {
+	t.Helper()
+
+	work := t.TempDir()
+	repo, err := git.PlainInit(work, false)
+	require.NoError(t, err)
+
+	bare := t.TempDir()
+	_, err = git.PlainInit(bare, true)
+	require.NoError(t, err)
+
+	_, err = repo.CreateRemote(&gitconfig.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{bare},
+	})
+	require.NoError(t, err)
+
+	return &templateRepo{
+		t:    t,
+		work: work,
+		repo: repo,
+		bare: bare,
+	}
+}
+
+// URL is the file:// URL of the bare repository
+func (r *templateRepo) URL() string {
+	return "file://" + filepath.ToSlash(r.bare)
+}
+
+// commit writes the files into the work tree and commits them, returning the commit hash. Files with empty content
+// are removed
+func (r *templateRepo) commit(files map[string]string) string {
+	r.t.Helper()
+
+	wt, err := r.repo.Worktree()
+	require.NoError(r.t, err)
+
+	for name, content := range files {
+		if content == "" {
+			_, err := wt.Remove(name)
+			require.NoError(r.t, err)
+			continue
+		}
+
+		path := filepath.Join(r.work, filepath.FromSlash(name))
+		require.NoError(r.t, os.MkdirAll(filepath.Dir(path), 0775))
+		require.NoError(r.t, os.WriteFile(path, []byte(content), 0664))
+		_, err := wt.Add(name)
+		require.NoError(r.t, err)
+	}
+
+	hash, err := wt.Commit("update templates", &git.CommitOptions{
+		Author: &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+	})
+	require.NoError(r.t, err)
+
+	return hash.String()
+}
+
+// tag creates an annotated tag at HEAD
+func (r *templateRepo) tag(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	require.NoError(r.t, err)
+
+	_, err = r.repo.CreateTag(name, head.Hash(), &git.CreateTagOptions{
+		Tagger:  &object.Signature{Name: "test", Email: "test@example.com", When: time.Now()},
+		Message: name,
+	})
+	require.NoError(r.t, err)
+}
+
+// branch creates a branch at HEAD
+func (r *templateRepo) branch(name string) {
+	r.t.Helper()
+
+	head, err := r.repo.Head()
+	require.NoError(r.t, err)
+
+	require.NoError(r.t, r.repo.Storer.SetReference(plumbing.NewHashReference(plumbing.NewBranchReferenceName(name), head.Hash())))
+}
+
+// push publishes every branch and tag to the bare repository
+func (r *templateRepo) push() {
+	r.t.Helper()
+
+	err := r.repo.Push(&git.PushOptions{
+		RemoteName: git.DefaultRemoteName,
+		RefSpecs: []gitconfig.RefSpec{
+			"+refs/heads/*:refs/heads/*",
+			"+refs/tags/*:refs/tags/*",
+		},
+	})
+	if !errors.Is(err, git.NoErrAlreadyUpToDate) {
+		require.NoError(r.t, err)
+	}
+}
+
+// newTemplateSkeley returns a Skeley for the named template in src
+func newTemplateSkeley(t *testing.T, src fs.FS, name string) *Skeley {
+	t.Helper()
+
+	inpFS, err := fs.Sub(src, name)
+	require.NoError(t, err)
+
+	return NewSkeley(SkeleyConfig{
+		InputFS:    inpFS,
+		SourceFS:   src,
+		Template:   name,
+		OutputPath: t.TempDir(),
+	})
+}
+
+// findFromEnv finds the named template in the sources configured by flags and environment variables, returning its
+// source
+func findFromEnv(name string) (*Source, error) {
+	sources, err := SourcesFromEnv(zerolog.Nop())
+	if err != nil {
+		return nil, err
+	}
+
+	source, _, err := sources.Find(name)
+	return source, err
+}
@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"bytes"
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strings"
+	"sync"
+
+	"github.com/rs/zerolog"
+)
+
+type hookRunner struct {
+	log zerolog.Logger
+	dir string
+}
+
+func newHookRunner(logger zerolog.Logger, dir string) *hookRunner {
+	return &hookRunner{
+		log: logger,
+		dir: dir,
+	}
+}
+
+// run executes each command in order in the runner's directory, stopping at the first failure
+func (h *hookRunner) run(stage string, cmds []string) error {
+	for _, c := range cmds {
+		log := h.log.With().Str("stage", stage).Str("cmd", c).Logger()
+		log.Debug().Msg("running hook")
+
+		cmd := shellCommand(c)
+		cmd.Dir = h.dir
+
+		stdout := newLogWriter(log, "stdout")
+		stderr := newLogWriter(log, "stderr")
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		err := cmd.Run()
+		stdout.Flush()
+		stderr.Flush()
+		if err != nil {
+			log.Err(err).Msg("running hook")
+			return fmt.Errorf("error running %v command %q: %w", stage, c, err)
+		}
+	}
+
+	return nil
+}
+
+func shellCommand(c string) *exec.Cmd {
+	if runtime.GOOS == "windows" {
+		return exec.Command("cmd", "/C", c)
+	}
+	return exec.Command("sh", "-c", c)
+}
+
+// logWriter emits each line written to it as a log message
+type logWriter struct {
+	log    zerolog.Logger
+	stream string
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func newLogWriter(logger zerolog.Logger, stream string) *logWriter {
+	return &logWriter{
+		log:    logger,
+		stream: stream,
+	}
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Partial line, put it back until we get the rest of it
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			break
+		}
+		w.emit(line)
+	}
+
+	return len(p), nil
+}
+
+func (w *logWriter) Flush() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.buf.Len() > 0 {
+		w.emit(w.buf.String())
+		w.buf.Reset()
+	}
+}
+
+func (w *logWriter) emit(line string) {
+	line = strings.TrimRight(line, "\r\n")
+	w.log.Info().Str("stream", w.stream).Msg(line)
+}
@@ -0,0 +1,163 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHookRunner(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use posix shell commands")
+	}
+
+	t.Run("runs in directory", func(t *testing.T) {
+		dir := t.TempDir()
+
+		h := newHookRunner(zerolog.Nop(), dir)
+		require.NoError(t, h.run("pre-cmds", []string{
+			"echo hello > out.txt",
+			"echo world >> out.txt",
+		}))
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "hello\nworld\n", string(content))
+	})
+
+	t.Run("stops on failure", func(t *testing.T) {
+		dir := t.TempDir()
+
+		h := newHookRunner(zerolog.Nop(), dir)
+		err := h.run("post-cmds", []string{
+			"exit 3",
+			"touch never.txt",
+		})
+		require.ErrorContains(t, err, `error running post-cmds command "exit 3"`)
+		require.ErrorContains(t, err, "exit status 3")
+
+		_, err = os.Stat(filepath.Join(dir, "never.txt"))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("streams output to logger", func(t *testing.T) {
+		var buf bytes.Buffer
+
+		h := newHookRunner(zerolog.New(&buf), t.TempDir())
+		require.NoError(t, h.run("pre-cmds", []string{
+			"echo line-one; echo line-two 1>&2; printf no-newline",
+		}))
+
+		out := buf.String()
+		require.Contains(t, out, `"stream":"stdout","message":"line-one"`)
+		require.Contains(t, out, `"stream":"stderr","message":"line-two"`)
+		require.Contains(t, out, `"stream":"stdout","message":"no-newline"`)
+	})
+}
+
+func TestExecuteHooks(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("hook tests use posix shell commands")
+	}
+
+	t.Run("pre and post cmds", func(t *testing.T) {
+		dir := t.TempDir()
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/main.go", []byte("package main\n"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				not-module: true
+				pre-cmds:
+				  - test ! -f main.go && touch pre-ran
+				post-cmds:
+				  - test -f main.go && touch post-ran
+			`)),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		require.FileExists(t, filepath.Join(dir, "pre-ran"))
+		require.FileExists(t, filepath.Join(dir, "post-ran"))
+	})
+
+	t.Run("pre cmd creates go.mod", func(t *testing.T) {
+		dir := t.TempDir()
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/README.md", []byte("# {{ .Module }}\n"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				pre-cmds:
+				  - printf 'module example.com/app\n\ngo 1.20\n' > go.mod
+			`)),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "README.md"))
+		require.NoError(t, err)
+		require.Equal(t, "# example.com/app\n", string(content))
+	})
+
+	t.Run("template error stops pre cmds", func(t *testing.T) {
+		dir := t.TempDir()
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/README.md", []byte("# {{ .Name \n"), 0664))
+		require.NoError(t, inpFS.WriteFile("config.yaml", []byte("not-module: true\npre-cmds:\n  - touch ran\n"), 0664))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.Error(t, sk.Execute())
+		require.NoFileExists(t, filepath.Join(dir, "ran"))
+	})
+
+	t.Run("failing pre cmd stops render", func(t *testing.T) {
+		dir := t.TempDir()
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/main.go", []byte("package main\n"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				not-module: true
+				pre-cmds:
+				  - "false"
+			`)),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.Error(t, sk.Execute())
+		require.NoFileExists(t, filepath.Join(dir, "main.go"))
+	})
+}
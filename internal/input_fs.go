@@ -6,8 +6,10 @@ import (
 	"os"
 
 	"github.com/forensicanalysis/gitfs"
+	"github.com/go-git/go-billy/v5"
 	billymem "github.com/go-git/go-billy/v5/memfs"
 	git "github.com/go-git/go-git/v5"
+	gitconfig "github.com/go-git/go-git/v5/config"
 	"github.com/go-git/go-git/v5/plumbing"
 	"github.com/go-git/go-git/v5/plumbing/transport"
 	"github.com/go-git/go-git/v5/plumbing/transport/http"
@@ -19,71 +21,251 @@ import (
 )
 
 
-func InputFSFromEnv(logger zerolog.Logger, template string) (fs.FS, error) {
+// SourceInfo describes where a template was loaded from
+type SourceInfo struct {
+	// Name is the name of the source in the user config, if it came from there
+	Name     string            `json:"name,omitempty" yaml:"name,omitempty"`
+	Type     config.SourceType `json:"type" yaml:"type"`
+	Location string            `json:"location" yaml:"location"`
+	// Ref is the requested branch, tag or commit, if any
+	Ref string `json:"ref,omitempty" yaml:"ref,omitempty"`
+	// Commit is the commit the ref resolved to, for git sources
+	Commit string `json:"commit,omitempty" yaml:"commit,omitempty"`
+}
+
+// Source is a loaded template source
+type Source struct {
+	SourceInfo
+	// FS is the root of the source, containing every template
+	FS fs.FS
+}
+
+// SourceConfig is where to load a template source from, and how to authenticate to it
+type SourceConfig struct {
+	Name       string            `yaml:"name"`
+	Type       config.SourceType `yaml:"type"`
+	Location   string            `yaml:"location"`
+	Ref        string            `yaml:"ref,omitempty"`
+	BranchName string            `yaml:"branch-name,omitempty"`
+	Token      string            `yaml:"token,omitempty"`
+	TokenUser  string            `yaml:"token-user,omitempty"`
+	KeyPath    string            `yaml:"key-path,omitempty"`
+}
+
+func envSourceConfig() (SourceConfig, error) {
 	inputType, err := config.ParseSourceType(viper.GetString(config.InputType))
 	if err != nil {
-		return nil, err
+		return SourceConfig{}, err
 	}
 
-	switch inputType {
+	return SourceConfig{
+		Type:       inputType,
+		Location:   viper.GetString(config.TemplateDir),
+		Ref:        viper.GetString(config.Ref),
+		BranchName: viper.GetString(config.BranchName),
+		Token:      viper.GetString(config.Token),
+		TokenUser:  viper.GetString(config.TokenUser),
+		KeyPath:    viper.GetString(config.KeyPath),
+	}, nil
+}
+
+// LoadSource loads a template source, using the cache directory and offline settings from the environment for git
+// sources
+func LoadSource(logger zerolog.Logger, conf SourceConfig) (*Source, error) {
+	switch conf.Type {
 	case config.SourceTypeGit:
-		return fsFromGit(logger, template)
+		return sourceFromGit(logger, conf)
 	case config.SourceTypeLocal:
-		return fs.Sub(os.DirFS(viper.GetString(config.TemplateDir)), template)
+		return &Source{
+			SourceInfo: SourceInfo{
+				Name:     conf.Name,
+				Type:     config.SourceTypeLocal,
+				Location: conf.Location,
+			},
+			FS: os.DirFS(conf.Location),
+		}, nil
 	default:
-		return nil, fmt.Errorf("unhandled input type %v", inputType)
+		return nil, fmt.Errorf("unhandled input type %v", conf.Type)
 	}
 }
 
-func fsFromGit(logger zerolog.Logger, template string) (fs.FS, error) {
-	auth, err := authFromEnv(logger)
+func sourceFromGit(logger zerolog.Logger, conf SourceConfig) (*Source, error) {
+	auth, err := authFromConfig(logger, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	url := conf.Location
+	ref := conf.Ref
+	branch := conf.BranchName
+	if ref != "" && branch != "" {
+		return nil, fmt.Errorf("only one of %v and %v can be set", config.Ref, config.BranchName)
+	}
+
+	var (
+		workTree billy.Filesystem
+		commit   string
+	)
+	if cacheDir := viper.GetString(config.CacheDir); cacheDir != "" {
+		workTree, commit, err = NewRepoCache(logger, cacheDir).checkout(url, auth, ref, branch, viper.GetBool(config.Offline))
+	} else {
+		if viper.GetBool(config.Offline) {
+			return nil, fmt.Errorf("%v requires a %v", config.Offline, config.CacheDir)
+		}
+		workTree, commit, err = cloneToMemory(logger, url, auth, ref, branch)
+	}
 	if err != nil {
 		return nil, err
 	}
+	logger.Debug().Str("commit", commit).Msg("resolved template commit")
 
+	return &Source{
+		SourceInfo: SourceInfo{
+			Name:     conf.Name,
+			Type:     config.SourceTypeGit,
+			Location: url,
+			Ref:      firstNonEmpty(ref, branch),
+			Commit:   commit,
+		},
+		FS: &gitfs.GitFS{FS: workTree},
+	}, nil
+}
+
+// cloneToMemory clones the ref or branch of the repo without keeping it on disk, returning its worktree and the full
+// commit hash
+func cloneToMemory(logger zerolog.Logger, url string, auth transport.AuthMethod, ref string, branch string) (billy.Filesystem, string, error) {
 	opts := &git.CloneOptions{
-		URL: viper.GetString(config.TemplateDir),
+		URL: url,
 		Auth: auth,
 		Depth: 1,
 	}
-	if name := viper.GetString(config.BranchName); name != "" {
-		logger.Debug().Str("branch", name).Msg("checking out non-default branch")
-		opts.ReferenceName = plumbing.NewBranchReferenceName(name)
+	if branch != "" {
+		logger.Debug().Str("branch", branch).Msg("checking out non-default branch")
+		opts.ReferenceName = plumbing.NewBranchReferenceName(branch)
 		opts.SingleBranch = true
 	}
 
+	var commitRef string
+	if ref != "" {
+		refName, err := resolveRemoteRef(url, auth, ref)
+		if err != nil {
+			return nil, "", err
+		}
+		if refName != "" {
+			logger.Debug().Str("ref", ref).Str("reference", refName.String()).Msg("checking out remote reference")
+			opts.ReferenceName = refName
+			opts.SingleBranch = true
+		} else {
+			// Not a branch or tag, so it should be a commit. Those can't be fetched directly, so fetch everything
+			// and check it out afterwards
+			logger.Debug().Str("ref", ref).Msg("ref is not a branch or tag, treating it as a commit")
+			opts.Depth = 0
+			opts.NoCheckout = true
+			commitRef = ref
+		}
+	}
+
 	logger.Debug().Msg("cloning repo")
 	workTree := billymem.New()
-	_, err = git.Clone(memory.NewStorage(), workTree, opts)
+	repo, err := git.Clone(memory.NewStorage(), workTree, opts)
 	if err != nil {
-		return nil, fmt.Errorf("error cloning repo: %w", err)
+		return nil, "", fmt.Errorf("error cloning repo: %w", err)
 	}
 
-	ioFS := &gitfs.GitFS{FS: workTree}
-	outFS, err := fs.Sub(ioFS, template)
+	commit, err := checkoutCommit(repo, commitRef)
 	if err != nil {
-		return nil, err
+		return nil, "", err
+	}
+
+	return workTree, commit, nil
+}
+
+// resolveRemoteRef finds the tag or branch named ref on the remote, preferring tags. If there is neither, an empty
+// reference name is returned
+func resolveRemoteRef(url string, auth transport.AuthMethod, ref string) (plumbing.ReferenceName, error) {
+	remote := git.NewRemote(memory.NewStorage(), &gitconfig.RemoteConfig{
+		Name: git.DefaultRemoteName,
+		URLs: []string{url},
+	})
+
+	refs, err := remote.List(&git.ListOptions{Auth: auth})
+	if err != nil {
+		return "", fmt.Errorf("error listing remote references: %w", err)
+	}
+
+	candidates := []plumbing.ReferenceName{
+		plumbing.NewTagReferenceName(ref),
+		plumbing.NewBranchReferenceName(ref),
+	}
+	for _, want := range candidates {
+		for _, r := range refs {
+			if r.Name() == want {
+				return want, nil
+			}
+		}
+	}
+
+	return "", nil
+}
+
+// checkoutCommit checks out the (possibly abbreviated) commit, or the current HEAD if ref is empty, returning the full
+// commit hash
+func checkoutCommit(repo *git.Repository, ref string) (string, error) {
+	if ref == "" {
+		head, err := repo.Head()
+		if err != nil {
+			return "", fmt.Errorf("error reading HEAD: %w", err)
+		}
+
+		// HEAD could be an annotated tag when cloning a tag, so resolve it down to the commit
+		hash, err := repo.ResolveRevision(plumbing.Revision(head.Hash().String()))
+		if err != nil {
+			return "", fmt.Errorf("error resolving HEAD: %w", err)
+		}
+		return hash.String(), nil
+	}
+
+	hash, err := repo.ResolveRevision(plumbing.Revision(ref))
+	if err != nil {
+		return "", fmt.Errorf("error resolving ref %v: %w", ref, err)
+	}
+
+	wt, err := repo.Worktree()
+	if err != nil {
+		return "", err
+	}
+	if err := wt.Checkout(&git.CheckoutOptions{Hash: *hash, Force: true}); err != nil {
+		return "", fmt.Errorf("error checking out %v: %w", hash, err)
 	}
 
-	return outFS, nil
+	return hash.String(), nil
+}
+
+func firstNonEmpty(vals ...string) string {
+	for _, v := range vals {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
 }
 
-func authFromEnv(logger zerolog.Logger) (transport.AuthMethod, error) {
-	if viper.GetString(config.Token) != "" {
+func authFromConfig(logger zerolog.Logger, conf SourceConfig) (transport.AuthMethod, error) {
+	if conf.Token != "" {
 		logger.Debug().Msg("using token auth")
-		user := viper.GetString(config.TokenUser)
+		user := conf.TokenUser
 		if user == "" {
 			user = "_token"
 		}
 
 		return &http.BasicAuth{
 			Username: user,
-			Password: viper.GetString(config.Token),
+			Password: conf.Token,
 		}, nil
 	}
-	if viper.GetString(config.KeyPath) != "" {
+	if conf.KeyPath != "" {
 		logger.Debug().Msg("using SSH key auth")
-		keyBytes, err := os.ReadFile(viper.GetString(config.KeyPath))
+		keyBytes, err := os.ReadFile(conf.KeyPath)
 		if err != nil {
 			return nil, fmt.Errorf("error reading ssh key: %w", err)
 		}
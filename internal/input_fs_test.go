@@ -1,6 +1,8 @@
 package internal
 
 import (
+	"bytes"
+	"fmt"
 	"io/fs"
 	"os"
 	"path/filepath"
@@ -28,18 +30,28 @@ func TestGitFSIntegration(t *testing.T) {
 		require.NoError(t, err)
 		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), modContent, 0664))
 
+		t.Cleanup(viper.Reset)
 		viper.Set(config.TemplateDir, "https://github.com/nicjohnson145/skeley-remote-template-example.git")
 		viper.Set(config.InputType, config.SourceTypeGit)
 
-		inpFS, err := InputFSFromEnv(zerolog.Logger{}, "example")
+		sources, err := SourcesFromEnv(zerolog.Nop())
+		require.NoError(t, err)
+		source, template, err := sources.Find("example")
+		require.NoError(t, err)
+		inpFS, err := fs.Sub(source.FS, template)
 		require.NoError(t, err)
 
 		sk := NewSkeley(SkeleyConfig{
 			InputFS:    inpFS,
+			SourceFS:   source.FS,
+			SourceInfo: source.SourceInfo,
+			Template:   template,
 			OutputPath: dir,
 		})
 
 		require.NoError(t, sk.Execute())
+		// The manifest is covered by TestManifest
+		require.NoError(t, os.Remove(filepath.Join(dir, ManifestFile)))
 
 		// Everything in the output directory matches the expected (no extra files)
 		fsEqual(t, destFS, expectedFS)
@@ -47,3 +59,124 @@ func TestGitFSIntegration(t *testing.T) {
 		fsEqual(t, expectedFS, destFS)
 	})
 }
+
+func TestGitRef(t *testing.T) {
+	repo := newTemplateRepo(t)
+	v1 := repo.commit(map[string]string{
+		"example/config.yaml":   "name: Example\nnot-module: true\n",
+		"example/files/VERSION": "v1\n",
+	})
+	repo.tag("v1.0.0")
+	repo.branch("release")
+	v2 := repo.commit(map[string]string{"example/files/VERSION": "v2\n"})
+	v3 := repo.commit(map[string]string{"example/files/VERSION": "v3\n"})
+	repo.push()
+
+	readVersion := func(t *testing.T, source *Source) string {
+		t.Helper()
+		content, err := fs.ReadFile(source.FS, "example/files/VERSION")
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	testData := []struct {
+		name    string
+		ref     string
+		branch  string
+		version string
+		commit  string
+	}{
+		{name: "default branch", version: "v3\n", commit: v3},
+		{name: "tag", ref: "v1.0.0", version: "v1\n", commit: v1},
+		{name: "branch", ref: "release", version: "v1\n", commit: v1},
+		{name: "branch name", branch: "release", version: "v1\n", commit: v1},
+		{name: "full sha", ref: v2, version: "v2\n", commit: v2},
+		{name: "short sha", ref: v2[:7], version: "v2\n", commit: v2},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			viper.Set(config.InputType, config.SourceTypeGit)
+			viper.Set(config.TemplateDir, repo.URL())
+			viper.Set(config.Ref, tc.ref)
+			viper.Set(config.BranchName, tc.branch)
+
+			source, err := findFromEnv("example")
+			require.NoError(t, err)
+			require.Equal(t, tc.version, readVersion(t, source))
+			require.Equal(
+				t,
+				SourceInfo{
+					Type:     config.SourceTypeGit,
+					Location: repo.URL(),
+					Ref:      firstNonEmpty(tc.ref, tc.branch),
+					Commit:   tc.commit,
+				},
+				source.SourceInfo,
+			)
+		})
+	}
+
+	t.Run("unknown ref", func(t *testing.T) {
+		t.Cleanup(viper.Reset)
+		viper.Set(config.InputType, config.SourceTypeGit)
+		viper.Set(config.TemplateDir, repo.URL())
+		viper.Set(config.Ref, "nope")
+
+		_, err := findFromEnv("example")
+		require.ErrorContains(t, err, "error resolving ref nope")
+	})
+
+	t.Run("ref and branch", func(t *testing.T) {
+		t.Cleanup(viper.Reset)
+		viper.Set(config.InputType, config.SourceTypeGit)
+		viper.Set(config.TemplateDir, repo.URL())
+		viper.Set(config.Ref, "v1.0.0")
+		viper.Set(config.BranchName, "release")
+
+		_, err := findFromEnv("example")
+		require.EqualError(t, err, "only one of ref and branch-name can be set")
+	})
+
+	t.Run("plan records commit", func(t *testing.T) {
+		t.Cleanup(viper.Reset)
+		viper.Set(config.InputType, config.SourceTypeGit)
+		viper.Set(config.TemplateDir, repo.URL())
+		viper.Set(config.Ref, "v1.0.0")
+
+		source, err := findFromEnv("example")
+		require.NoError(t, err)
+		inpFS, err := fs.Sub(source.FS, "example")
+		require.NoError(t, err)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			SourceFS:   source.FS,
+			SourceInfo: source.SourceInfo,
+			Template:   "example",
+			OutputPath: t.TempDir(),
+		})
+		plan, err := sk.Plan()
+		require.NoError(t, err)
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, WritePlan(buf, plan, config.OutputFormatText))
+		require.Equal(t, fmt.Sprintf("source  %v@%v\ncreate  VERSION\n", repo.URL(), v1), buf.String())
+	})
+
+	t.Run("list templates", func(t *testing.T) {
+		t.Cleanup(viper.Reset)
+		viper.Set(config.InputType, config.SourceTypeGit)
+		viper.Set(config.TemplateDir, repo.URL())
+
+		source, err := findFromEnv("example")
+		require.NoError(t, err)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS: source.FS,
+		})
+		templates, err := sk.ListTemplates()
+		require.NoError(t, err)
+		require.Equal(t, []TemplateInfo{{Template: "example", Name: "Example"}}, templates)
+	})
+}
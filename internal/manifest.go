@@ -0,0 +1,107 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ManifestFile is written to the output directory, recording how the project was generated
+const ManifestFile = ".skeley.yaml"
+
+// Manifest records the template, source and answers a project was generated from
+type Manifest struct {
+	Template string     `yaml:"template"`
+	Source   SourceInfo `yaml:"source"`
+	// Version is the version of skeley that generated the project
+	Version string `yaml:"version"`
+	// Answers are the values of the template's variables, except for secrets
+	Answers map[string]any `yaml:"answers,omitempty"`
+	// Files maps the path of every file written from the template to the hash of the content the template rendered for
+	// it. Files that already existed and were kept aren't recorded
+	Files map[string]string `yaml:"files"`
+}
+
+// ReadManifest reads the manifest from a generated project
+func ReadManifest(dir string) (*Manifest, error) {
+	content, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("%v has no %v, it wasn't generated by skeley", dir, ManifestFile)
+		}
+		return nil, fmt.Errorf("error reading manifest: %w", err)
+	}
+
+	var m Manifest
+	if err := yaml.Unmarshal(content, &m); err != nil {
+		return nil, fmt.Errorf("error parsing manifest: %w", err)
+	}
+
+	return &m, nil
+}
+
+// writeManifest records the template, answers and the files holding the template's content
+func (s *Skeley) writeManifest(tmpl loadedTemplate, values map[string]any, files []PlannedFile) error {
+	m := Manifest{
+		Template: s.conf.Template,
+		Source:   s.conf.SourceInfo,
+		Version:  s.conf.Version,
+		Answers:  map[string]any{},
+		Files:    map[string]string{},
+	}
+
+	for _, d := range tmpl.conf.Variables {
+		if d.Secret {
+			continue
+		}
+		if val, ok := values[d.Name]; ok {
+			m.Answers[d.Name] = val
+		}
+	}
+
+	for _, f := range files {
+		hash, err := f.hash()
+		if err != nil {
+			return fmt.Errorf("error hashing %v: %w", f.Path, err)
+		}
+		m.Files[f.Path] = hash
+	}
+
+	content, err := yaml.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	s.log.Debug().Str("path", ManifestFile).Msg("writing manifest")
+	if err := os.WriteFile(s.outputFile(ManifestFile), content, 0664); err != nil {
+		s.log.Err(err).Msg("writing manifest")
+		return fmt.Errorf("error writing manifest: %w", err)
+	}
+
+	return nil
+}
+
+// hash is the sha256 of the rendered content, as `sha256:<hex>`
+func (f PlannedFile) hash() (string, error) {
+	r, err := f.open()
+	if err != nil {
+		return "", err
+	}
+	defer r.Close()
+
+	return hashReader(r)
+}
+
+func hashReader(r io.Reader) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, r); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("sha256:%x", h.Sum(nil)), nil
+}
@@ -0,0 +1,126 @@
+package internal
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestManifest(t *testing.T) {
+	newSource := func(t *testing.T) *memfs.FS {
+		src := memfs.New()
+		require.NoError(t, src.MkdirAll("service/files/assets", 0775))
+		require.NoError(t, src.WriteFile("service/config.yaml", []byte(dedent.Dedent(`
+			not-module: true
+			variables:
+			  - name: Name
+			  - name: Port
+			    type: int
+			    default: 8080
+			  - name: Token
+			    secret: true
+		`)), 0664))
+		require.NoError(t, src.WriteFile("service/files/README.md", []byte("# {{ .Name }}\n"), 0664))
+		require.NoError(t, src.WriteFile("service/files/assets/logo.png", []byte("\x89PNG\x00"), 0664))
+		return src
+	}
+
+	t.Run("written after execute", func(t *testing.T) {
+		dir := t.TempDir()
+		src := newSource(t)
+		inpFS, err := src.Sub("service")
+		require.NoError(t, err)
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:  inpFS,
+			SourceFS: src,
+			Template: "service",
+			SourceInfo: SourceInfo{
+				Type:     config.SourceTypeGit,
+				Location: "https://example.com/templates.git",
+				Ref:      "v1.0.0",
+				Commit:   "0123456789abcdef0123456789abcdef01234567",
+			},
+			OutputPath: dir,
+			Values:     map[string]any{"Name": "billing", "Token": "s3cret"},
+			Version:    "v1.2.3",
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, ManifestFile))
+		require.NoError(t, err)
+		sum := func(s string) string {
+			return fmt.Sprintf("sha256:%x", sha256.Sum256([]byte(s)))
+		}
+		require.Equal(
+			t,
+			fmt.Sprintf(dedent.Dedent(`
+				template: service
+				source:
+				    type: git
+				    location: https://example.com/templates.git
+				    ref: v1.0.0
+				    commit: 0123456789abcdef0123456789abcdef01234567
+				version: v1.2.3
+				answers:
+				    Name: billing
+				    Port: 8080
+				files:
+				    README.md: %v
+				    assets/logo.png: %v
+			`)[1:], sum("# billing\n"), sum("\x89PNG\x00")),
+			string(content),
+		)
+
+		m, err := ReadManifest(dir)
+		require.NoError(t, err)
+		require.Equal(t, "service", m.Template)
+		require.Equal(t, "0123456789abcdef0123456789abcdef01234567", m.Source.Commit)
+		require.NotContains(t, m.Answers, "Token")
+
+		for path, hash := range m.Files {
+			f, err := os.Open(filepath.Join(dir, filepath.FromSlash(path)))
+			require.NoError(t, err)
+			actual, err := hashReader(f)
+			require.NoError(t, f.Close())
+			require.NoError(t, err)
+			require.Equal(t, hash, actual, path)
+		}
+	})
+
+	t.Run("skipped files not recorded", func(t *testing.T) {
+		sk := newTemplateSkeley(t, newSource(t), "service")
+		sk.conf.SourceInfo = SourceInfo{Type: config.SourceTypeLocal, Location: "templates"}
+		sk.conf.Values = map[string]any{"Name": "billing"}
+		sk.conf.OnConflict = config.ConflictPolicySkip
+		dir := sk.conf.OutputPath
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Billing\n"), 0664))
+		require.NoError(t, sk.Execute())
+
+		m, err := ReadManifest(dir)
+		require.NoError(t, err)
+		require.Contains(t, m.Files, "assets/logo.png")
+		require.NotContains(t, m.Files, "README.md")
+	})
+
+	t.Run("not written by plan", func(t *testing.T) {
+		sk := newTemplateSkeley(t, newSource(t), "service")
+		sk.conf.Values = map[string]any{"Name": "billing", "Token": "s3cret"}
+		_, err := sk.Plan()
+		require.NoError(t, err)
+		require.NoFileExists(t, filepath.Join(sk.conf.OutputPath, ManifestFile))
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		dir := t.TempDir()
+		_, err := ReadManifest(dir)
+		require.EqualError(t, err, dir+" has no .skeley.yaml, it wasn't generated by skeley")
+	})
+}
@@ -0,0 +1,141 @@
+package internal
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+const (
+	conflictStart = "<<<<<<< current\n"
+	conflictSep   = "=======\n"
+	conflictEnd   = ">>>>>>> template\n"
+)
+
+// hunk replaces the base lines [start, end) with lines. Insertions have start == end
+type hunk struct {
+	start int
+	end   int
+	lines []string
+	ours  bool
+}
+
+// merge3 merges the changes made from base to ours and from base to theirs, line by line. Each side's changes are
+// diffed against base, and changes to separate base lines are both applied. Where both sides changed the same base
+// lines, or inserted at the same place, differently, both versions are kept between conflict markers, ours first, and
+// conflict is true
+func merge3(base string, ours string, theirs string) (merged string, conflict bool) {
+	baseLines := splitLines(base)
+
+	hunks := append(diffHunks(baseLines, splitLines(ours), true), diffHunks(baseLines, splitLines(theirs), false)...)
+	sort.SliceStable(hunks, func(i, j int) bool {
+		if hunks[i].start != hunks[j].start {
+			return hunks[i].start < hunks[j].start
+		}
+		return hunks[i].end < hunks[j].end
+	})
+
+	var out strings.Builder
+	pos := 0
+	for i := 0; i < len(hunks); {
+		// Group the hunks touching the same base lines, which have to be resolved together
+		start, end := hunks[i].start, hunks[i].end
+		j := i + 1
+		for ; j < len(hunks); j++ {
+			h := hunks[j]
+			if h.start >= end && h.start != start {
+				break
+			}
+			if h.end > end {
+				end = h.end
+			}
+		}
+		group := hunks[i:j]
+		i = j
+
+		writeLines(&out, baseLines[pos:start])
+		pos = end
+
+		ourLines, ourChanged := applyHunks(baseLines, start, end, group, true)
+		theirLines, theirChanged := applyHunks(baseLines, start, end, group, false)
+		switch {
+		case !ourChanged:
+			writeLines(&out, theirLines)
+		case !theirChanged, equalLines(ourLines, theirLines):
+			writeLines(&out, ourLines)
+		default:
+			conflict = true
+			out.WriteString(conflictStart)
+			writeLines(&out, terminated(ourLines))
+			out.WriteString(conflictSep)
+			writeLines(&out, terminated(theirLines))
+			out.WriteString(conflictEnd)
+		}
+	}
+	writeLines(&out, baseLines[pos:])
+
+	return out.String(), conflict
+}
+
+// diffHunks returns the changes from base to side
+func diffHunks(base []string, side []string, ours bool) []hunk {
+	hunks := []hunk{}
+	if len(base) == 0 && len(side) == 0 {
+		return hunks
+	}
+
+	for _, op := range difflib.NewMatcherWithJunk(base, side, false, nil).GetOpCodes() {
+		if op.Tag == 'e' {
+			continue
+		}
+		hunks = append(hunks, hunk{start: op.I1, end: op.I2, lines: side[op.J1:op.J2], ours: ours})
+	}
+	return hunks
+}
+
+// applyHunks returns one side's version of the base lines [start, end), and whether that side changed them
+func applyHunks(base []string, start int, end int, group []hunk, ours bool) ([]string, bool) {
+	lines := []string{}
+	pos := start
+	changed := false
+	for _, h := range group {
+		if h.ours != ours {
+			continue
+		}
+		lines = append(lines, base[pos:h.start]...)
+		lines = append(lines, h.lines...)
+		pos = h.end
+		changed = true
+	}
+	lines = append(lines, base[pos:end]...)
+	return lines, changed
+}
+
+func equalLines(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func writeLines(out *strings.Builder, lines []string) {
+	for _, l := range lines {
+		out.WriteString(l)
+	}
+}
+
+// terminated ensures the last line ends with a newline, so a conflict marker after it starts on its own line
+func terminated(lines []string) []string {
+	if len(lines) == 0 || strings.HasSuffix(lines[len(lines)-1], "\n") {
+		return lines
+	}
+	out := append([]string{}, lines...)
+	out[len(out)-1] += "\n"
+	return out
+}
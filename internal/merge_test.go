@@ -0,0 +1,114 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMerge3(t *testing.T) {
+	testData := []struct {
+		name     string
+		base     string
+		ours     string
+		theirs   string
+		merged   string
+		conflict bool
+	}{
+		{
+			name:   "unchanged",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nb\nc\n",
+			merged: "a\nb\nc\n",
+		},
+		{
+			name:   "only theirs changed",
+			base:   "a\nb\nc\n",
+			ours:   "a\nb\nc\n",
+			theirs: "a\nB\nc\nd\n",
+			merged: "a\nB\nc\nd\n",
+		},
+		{
+			name:   "only ours changed",
+			base:   "a\nb\nc\n",
+			ours:   "z\na\nb\n",
+			theirs: "a\nb\nc\n",
+			merged: "z\na\nb\n",
+		},
+		{
+			name:   "separate changes",
+			base:   "a\nb\nc\nd\ne\n",
+			ours:   "A\nb\nc\nd\ne\n",
+			theirs: "a\nb\nc\nd\nE\nf\n",
+			merged: "A\nb\nc\nd\nE\nf\n",
+		},
+		{
+			name:   "same change",
+			base:   "a\nb\nc\n",
+			ours:   "a\nB\nc\n",
+			theirs: "a\nB\nc\n",
+			merged: "a\nB\nc\n",
+		},
+		{
+			name:     "overlapping changes",
+			base:     "a\nb\nc\n",
+			ours:     "a\nours\nc\n",
+			theirs:   "a\ntheirs\nc\n",
+			merged:   "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> template\nc\n",
+			conflict: true,
+		},
+		{
+			name:     "no trailing newline",
+			base:     "a\nb",
+			ours:     "a\nours",
+			theirs:   "a\ntheirs",
+			merged:   "a\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> template\n",
+			conflict: true,
+		},
+		{
+			name:     "both added",
+			base:     "",
+			ours:     "same\nours\n",
+			theirs:   "same\ntheirs\n",
+			merged:   "<<<<<<< current\nsame\nours\n=======\nsame\ntheirs\n>>>>>>> template\n",
+			conflict: true,
+		},
+		{
+			name:   "repeated lines",
+			base:   "x\nx\nx\n",
+			ours:   "x\ny\nx\nx\n",
+			theirs: "x\nx\nx\nz\n",
+			merged: "x\ny\nx\nx\nz\n",
+		},
+		{
+			name:   "repeated closing braces",
+			base:   "func a() {\n}\n\nfunc b() {\n}\n",
+			ours:   "func a() {\n\tone()\n}\n\nfunc b() {\n}\n",
+			theirs: "func a() {\n}\n\nfunc b() {\n\ttwo()\n}\n",
+			merged: "func a() {\n\tone()\n}\n\nfunc b() {\n\ttwo()\n}\n",
+		},
+		{
+			name:   "repeated blank lines",
+			base:   "a\n\nb\n\nc\n\nd\n",
+			ours:   "a\n\nB\n\nc\n\nd\n",
+			theirs: "a\n\nb\n\nc\n\nD\n\n",
+			merged: "a\n\nB\n\nc\n\nD\n\n",
+		},
+		{
+			name:     "same insertion point",
+			base:     "x\nx\n",
+			ours:     "x\nours\nx\n",
+			theirs:   "x\ntheirs\nx\n",
+			merged:   "x\n<<<<<<< current\nours\n=======\ntheirs\n>>>>>>> template\nx\n",
+			conflict: true,
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			merged, conflict := merge3(tc.base, tc.ours, tc.theirs)
+			require.Equal(t, tc.merged, merged)
+			require.Equal(t, tc.conflict, conflict)
+		})
+	}
+}
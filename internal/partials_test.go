@@ -0,0 +1,85 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPartials(t *testing.T) {
+	newInput := func(t *testing.T) *memfs.FS {
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files/cmd", 0775))
+		require.NoError(t, inpFS.MkdirAll("partials/go", 0775))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte("not-module: true\nvariables:\n  - name: Owner\n"),
+			0664,
+		))
+		require.NoError(t, inpFS.WriteFile("partials/license-header.tmpl", []byte("// Copyright {{ .Owner }}\n"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"partials/go/logging.tmpl",
+			[]byte(`{{ define "logger" }}log := zerolog.New(os.Stderr){{ end }}`),
+			0664,
+		))
+		return inpFS
+	}
+
+	t.Run("used from files", func(t *testing.T) {
+		inpFS := newInput(t)
+		require.NoError(t, inpFS.WriteFile(
+			"files/main.go",
+			[]byte("{{ template \"license-header\" . }}package main\n"),
+			0664,
+		))
+		require.NoError(t, inpFS.WriteFile(
+			"files/cmd/root.go",
+			[]byte("{{ template \"license-header\" . }}package cmd\n\n{{ template \"logger\" }}\n"),
+			0664,
+		))
+		require.NoError(t, inpFS.WriteFile(
+			"files/index.html",
+			[]byte("<!-- {{ template \"license-header\" . }} -->"),
+			0664,
+		))
+
+		dir := t.TempDir()
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+			Values: map[string]any{
+				"Owner": "Acme",
+			},
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "main.go"))
+		require.NoError(t, err)
+		require.Equal(t, "// Copyright Acme\npackage main\n", string(content))
+
+		content, err = os.ReadFile(filepath.Join(dir, "cmd", "root.go"))
+		require.NoError(t, err)
+		require.Equal(t, "// Copyright Acme\npackage cmd\n\nlog := zerolog.New(os.Stderr)\n", string(content))
+
+		require.FileExists(t, filepath.Join(dir, "index.html"))
+
+		// Partials are never output
+		require.NoFileExists(t, filepath.Join(dir, "license-header.tmpl"))
+		require.NoDirExists(t, filepath.Join(dir, "partials"))
+		require.NoDirExists(t, filepath.Join(dir, "go"))
+	})
+
+	t.Run("name collision", func(t *testing.T) {
+		inpFS := newInput(t)
+		require.NoError(t, inpFS.WriteFile("files/license-header", []byte("oops"), 0664))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: t.TempDir(),
+		})
+		require.EqualError(t, sk.Execute(), "file license-header has the same name as a partial")
+	})
+}
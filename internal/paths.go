@@ -0,0 +1,49 @@
+package internal
+
+import (
+	"fmt"
+	"path"
+	"path/filepath"
+	"strings"
+	"text/template"
+)
+
+// renderPath renders each segment of a slash separated template path. If any segment renders empty, ok is false and
+// the path should be skipped. The rendered path must stay within the output directory
+func renderPath(name string, data any, funcMap template.FuncMap) (string, bool, error) {
+	segments := strings.Split(name, "/")
+	rendered := make([]string, 0, len(segments))
+
+	for _, seg := range segments {
+		if !strings.Contains(seg, "{{") {
+			rendered = append(rendered, seg)
+			continue
+		}
+
+		t, err := template.New(name).Funcs(funcMap).Parse(seg)
+		if err != nil {
+			return "", false, fmt.Errorf("error parsing path %v: %w", name, err)
+		}
+
+		var out strings.Builder
+		if err := t.Execute(&out, data); err != nil {
+			return "", false, fmt.Errorf("error rendering path %v: %w", name, err)
+		}
+
+		val := strings.TrimSpace(out.String())
+		if val == "" {
+			return "", false, nil
+		}
+		if path.IsAbs(val) || filepath.IsAbs(val) {
+			return "", false, fmt.Errorf("path %v renders to absolute path segment %v", name, val)
+		}
+		rendered = append(rendered, val)
+	}
+
+	out := path.Join(rendered...)
+	if !filepath.IsLocal(filepath.FromSlash(out)) {
+		return "", false, fmt.Errorf("path %v renders to %v, which is outside the output directory", name, out)
+	}
+
+	return out, true, nil
+}
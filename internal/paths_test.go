@@ -0,0 +1,124 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"text/template"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRenderPath(t *testing.T) {
+	data := map[string]any{
+		"BinaryName": "foo",
+		"Service":    "billing",
+		"Empty":      "",
+		"Module":     "github.com/foo/bar",
+		"Abs":        "/etc",
+		"Parent":     "..",
+	}
+
+	testData := []struct {
+		name     string
+		path     string
+		expected string
+		skip     bool
+		err      string
+	}{
+		{
+			name:     "plain",
+			path:     "cmd/root.go",
+			expected: "cmd/root.go",
+		},
+		{
+			name:     "directory segment",
+			path:     "cmd/{{ .BinaryName }}/main.go",
+			expected: "cmd/foo/main.go",
+		},
+		{
+			name:     "file segment",
+			path:     "internal/{{ .Service }}.go",
+			expected: "internal/billing.go",
+		},
+		{
+			name:     "multiple segments",
+			path:     "internal/{{ .Service }}/{{ .BinaryName }}_test.go",
+			expected: "internal/billing/foo_test.go",
+		},
+		{
+			name:     "value with slashes",
+			path:     "vendor/{{ .Module }}/doc.go",
+			expected: "vendor/github.com/foo/bar/doc.go",
+		},
+		{
+			name: "empty file",
+			path: "internal/{{ .Empty }}",
+			skip: true,
+		},
+		{
+			name: "empty directory",
+			path: "{{ if .Empty }}docker{{ end }}/Dockerfile",
+			skip: true,
+		},
+		{
+			name: "absolute",
+			path: "{{ .Abs }}/passwd",
+			err:  "path {{ .Abs }}/passwd renders to absolute path segment /etc",
+		},
+		{
+			name: "parent",
+			path: "{{ .Parent }}/escape.go",
+			err:  "path {{ .Parent }}/escape.go renders to ../escape.go, which is outside the output directory",
+		},
+		{
+			name: "nested parent",
+			path: "a/{{ .Parent }}/{{ .Parent }}/escape.go",
+			err:  "path a/{{ .Parent }}/{{ .Parent }}/escape.go renders to ../escape.go, which is outside the output directory",
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok, err := renderPath(tc.path, data, template.FuncMap{})
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, !tc.skip, ok)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestExecuteTemplatedPaths(t *testing.T) {
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/foo/bar\n\ngo 1.20\n"), 0664))
+
+	inpFS := memfs.New()
+	require.NoError(t, inpFS.MkdirAll("files/cmd/{{ .BinaryName }}", 0775))
+	require.NoError(t, inpFS.MkdirAll("files/{{ if .UseDocker }}docker{{ end }}", 0775))
+	require.NoError(t, inpFS.WriteFile("files/cmd/{{ .BinaryName }}/main.go", []byte("package main\n"), 0664))
+	require.NoError(t, inpFS.WriteFile("files/{{ if .UseDocker }}docker{{ end }}/Dockerfile", []byte("FROM scratch\n"), 0664))
+	require.NoError(t, inpFS.WriteFile(
+		"config.yaml",
+		[]byte(dedent.Dedent(`
+			variables:
+			  - name: UseDocker
+			    type: bool
+		`)),
+		0664,
+	))
+
+	sk := NewSkeley(SkeleyConfig{
+		InputFS:    inpFS,
+		OutputPath: dir,
+	})
+	require.NoError(t, sk.Execute())
+
+	require.FileExists(t, filepath.Join(dir, "cmd", "bar", "main.go"))
+	require.NoDirExists(t, filepath.Join(dir, "docker"))
+	require.NoDirExists(t, filepath.Join(dir, "{{ if .UseDocker }}docker{{ end }}"))
+}
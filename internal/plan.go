@@ -0,0 +1,135 @@
+package internal
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/nicjohnson145/skeley/config"
+)
+
+//go:generate go-enum -f $GOFILE -marshal -names
+
+/*
+ENUM(
+create
+overwrite
+unchanged
+skip
+backup
+)
+*/
+type PlanAction string
+
+// PlannedFile is a single file that executing the template produces
+type PlannedFile struct {
+	// Path is the slash separated path of the file, relative to the output directory
+	Path   string     `json:"path"`
+	Action PlanAction `json:"action"`
+
+	// source is the name of the file under `files/`, in sourceFS
+	source   string
+	sourceFS fs.FS
+	// content is the rendered file, unset for copied files which are streamed from source instead
+	content []byte
+	copy    bool
+}
+
+// Plan describes everything executing the template does, without having done any of it
+type Plan struct {
+	Source   *SourceInfo   `json:"source,omitempty"`
+	Files    []PlannedFile `json:"files"`
+	PreCmds  []string      `json:"pre-cmds,omitempty"`
+	PostCmds []string      `json:"post-cmds,omitempty"`
+}
+
+func (f PlannedFile) open() (io.ReadCloser, error) {
+	if f.copy {
+		return f.sourceFS.Open(f.source)
+	}
+	return io.NopCloser(bytes.NewReader(f.content)), nil
+}
+
+// WritePlan prints the plan in the given format
+func WritePlan(w io.Writer, plan *Plan, format config.OutputFormat) error {
+	switch format {
+	case config.OutputFormatJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(plan)
+	case config.OutputFormatText:
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		if plan.Source != nil && plan.Source.Commit != "" {
+			fmt.Fprintf(tw, "source\t%v@%v\n", plan.Source.Location, plan.Source.Commit)
+		}
+		for _, c := range plan.PreCmds {
+			fmt.Fprintf(tw, "run\t%v\n", c)
+		}
+		for _, f := range plan.Files {
+			fmt.Fprintf(tw, "%v\t%v\n", f.Action, f.Path)
+		}
+		for _, c := range plan.PostCmds {
+			fmt.Fprintf(tw, "run\t%v\n", c)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unhandled output format %v", format)
+	}
+}
+
+// planAction decides what writing the content to path would do
+func planAction(path string, content io.Reader) (PlanAction, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return PlanActionCreate, nil
+		}
+		return "", err
+	}
+	defer f.Close()
+
+	same, err := sameContent(f, content)
+	if err != nil {
+		return "", err
+	}
+	if same {
+		return PlanActionUnchanged, nil
+	}
+	return PlanActionOverwrite, nil
+}
+
+func sameContent(left io.Reader, right io.Reader) (bool, error) {
+	lr := bufio.NewReader(left)
+	rr := bufio.NewReader(right)
+
+	for {
+		lb, lerr := lr.ReadByte()
+		rb, rerr := rr.ReadByte()
+		if lerr != nil || rerr != nil {
+			if lerr == io.EOF && rerr == io.EOF {
+				return true, nil
+			}
+			if lerr != nil && lerr != io.EOF {
+				return false, lerr
+			}
+			if rerr != nil && rerr != io.EOF {
+				return false, rerr
+			}
+			return false, nil
+		}
+		if lb != rb {
+			return false, nil
+		}
+	}
+}
+
+func (s *Skeley) outputFile(path string) string {
+	return filepath.Join(s.outputPath, filepath.FromSlash(path))
+}
@@ -0,0 +1,85 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version: 0.5.8
+// Revision: 3d844c8ecc59661ed7aa17bfd65727bc06a60ad8
+// Build Date: 2023-09-18T14:55:21Z
+// Built By: goreleaser
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// PlanActionCreate is a PlanAction of type create.
+	PlanActionCreate PlanAction = "create"
+	// PlanActionOverwrite is a PlanAction of type overwrite.
+	PlanActionOverwrite PlanAction = "overwrite"
+	// PlanActionUnchanged is a PlanAction of type unchanged.
+	PlanActionUnchanged PlanAction = "unchanged"
+	// PlanActionSkip is a PlanAction of type skip.
+	PlanActionSkip PlanAction = "skip"
+	// PlanActionBackup is a PlanAction of type backup.
+	PlanActionBackup PlanAction = "backup"
+)
+
+var ErrInvalidPlanAction = fmt.Errorf("not a valid PlanAction, try [%s]", strings.Join(_PlanActionNames, ", "))
+
+var _PlanActionNames = []string{
+	string(PlanActionCreate),
+	string(PlanActionOverwrite),
+	string(PlanActionUnchanged),
+	string(PlanActionSkip),
+	string(PlanActionBackup),
+}
+
+// PlanActionNames returns a list of possible string values of PlanAction.
+func PlanActionNames() []string {
+	tmp := make([]string, len(_PlanActionNames))
+	copy(tmp, _PlanActionNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x PlanAction) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x PlanAction) IsValid() bool {
+	_, err := ParsePlanAction(string(x))
+	return err == nil
+}
+
+var _PlanActionValue = map[string]PlanAction{
+	"create":    PlanActionCreate,
+	"overwrite": PlanActionOverwrite,
+	"unchanged": PlanActionUnchanged,
+	"skip":      PlanActionSkip,
+	"backup":    PlanActionBackup,
+}
+
+// ParsePlanAction attempts to convert a string to a PlanAction.
+func ParsePlanAction(name string) (PlanAction, error) {
+	if x, ok := _PlanActionValue[name]; ok {
+		return x, nil
+	}
+	return PlanAction(""), fmt.Errorf("%s is %w", name, ErrInvalidPlanAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x PlanAction) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *PlanAction) UnmarshalText(text []byte) error {
+	tmp, err := ParsePlanAction(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
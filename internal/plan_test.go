@@ -0,0 +1,181 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPlan(t *testing.T) {
+	newInput := func(t *testing.T) *memfs.FS {
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files/cmd/{{ .BinaryName }}", 0775))
+		require.NoError(t, inpFS.WriteFile("files/cmd/{{ .BinaryName }}/main.go", []byte("package main\n"), 0664))
+		require.NoError(t, inpFS.WriteFile("files/README.md", []byte("# {{ .BinaryName }}\n"), 0664))
+		require.NoError(t, inpFS.WriteFile("files/Dockerfile", []byte("FROM scratch\n"), 0664))
+		require.NoError(t, inpFS.WriteFile("files/logo.png", []byte("\x89PNG\x00"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				pre-cmds:
+				  - touch pre-ran
+				post-cmds:
+				  - touch post-ran
+				conditions:
+				  Dockerfile: "false"
+			`)),
+			0664,
+		))
+		return inpFS
+	}
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/foo/bar\n\ngo 1.20\n"), 0664))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# bar\n"), 0664))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte("old"), 0664))
+
+	sk := NewSkeley(SkeleyConfig{
+		InputFS:    newInput(t),
+		OutputPath: dir,
+	})
+
+	plan, err := sk.Plan()
+	require.NoError(t, err)
+
+	actions := map[string]PlanAction{}
+	for _, f := range plan.Files {
+		actions[f.Path] = f.Action
+	}
+	require.Equal(
+		t,
+		map[string]PlanAction{
+			"README.md":       PlanActionUnchanged,
+			"cmd/bar/main.go": PlanActionCreate,
+			"logo.png":        PlanActionOverwrite,
+		},
+		actions,
+	)
+	require.Equal(t, []string{"touch pre-ran"}, plan.PreCmds)
+	require.Equal(t, []string{"touch post-ran"}, plan.PostCmds)
+
+	t.Run("nothing written", func(t *testing.T) {
+		require.NoDirExists(t, filepath.Join(dir, "cmd"))
+		require.NoFileExists(t, filepath.Join(dir, "pre-ran"))
+		require.NoFileExists(t, filepath.Join(dir, "post-ran"))
+
+		content, err := os.ReadFile(filepath.Join(dir, "logo.png"))
+		require.NoError(t, err)
+		require.Equal(t, "old", string(content))
+	})
+
+	t.Run("text", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, WritePlan(&out, plan, config.OutputFormatText))
+		require.Equal(
+			t,
+			dedent.Dedent(`
+				run        touch pre-ran
+				unchanged  README.md
+				create     cmd/bar/main.go
+				overwrite  logo.png
+				run        touch post-ran
+			`)[1:],
+			out.String(),
+		)
+	})
+
+	t.Run("json", func(t *testing.T) {
+		var out bytes.Buffer
+		require.NoError(t, WritePlan(&out, plan, config.OutputFormatJson))
+		require.JSONEq(
+			t,
+			`{
+				"files": [
+					{"path": "README.md", "action": "unchanged"},
+					{"path": "cmd/bar/main.go", "action": "create"},
+					{"path": "logo.png", "action": "overwrite"}
+				],
+				"pre-cmds": ["touch pre-ran"],
+				"post-cmds": ["touch post-ran"]
+			}`,
+			out.String(),
+		)
+	})
+}
+
+func TestPlanCreatesGoMod(t *testing.T) {
+	inpFS := memfs.New()
+	require.NoError(t, inpFS.MkdirAll("files/cmd/app", 0775))
+	require.NoError(t, inpFS.WriteFile("files/cmd/app/main.go", []byte("package main\n"), 0664))
+
+	dir := t.TempDir()
+	sk := NewSkeley(SkeleyConfig{
+		InputFS:    inpFS,
+		OutputPath: dir,
+		Module:     "github.com/foo/bar",
+		GoVersion:  "1.20",
+	})
+
+	plan, err := sk.Plan()
+	require.NoError(t, err)
+	require.NoFileExists(t, filepath.Join(dir, "go.mod"))
+
+	var out bytes.Buffer
+	require.NoError(t, WritePlan(&out, plan, config.OutputFormatJson))
+	require.JSONEq(
+		t,
+		`{
+			"files": [
+				{"path": "go.mod", "action": "create"},
+				{"path": "cmd/app/main.go", "action": "create"}
+			]
+		}`,
+		out.String(),
+	)
+
+	require.NoError(t, sk.Execute())
+	content, err := os.ReadFile(filepath.Join(dir, "go.mod"))
+	require.NoError(t, err)
+	require.Equal(t, "module github.com/foo/bar\n\ngo 1.20\n", string(content))
+
+	t.Run("existing go.mod", func(t *testing.T) {
+		plan, err := sk.Plan()
+		require.NoError(t, err)
+		for _, f := range plan.Files {
+			require.NotEqual(t, "go.mod", f.Path)
+		}
+	})
+}
+
+func TestPlanDuplicateOutput(t *testing.T) {
+	inpFS := memfs.New()
+	require.NoError(t, inpFS.MkdirAll("files", 0775))
+	require.NoError(t, inpFS.WriteFile("files/{{ .A }}.go", []byte(""), 0664))
+	require.NoError(t, inpFS.WriteFile("files/{{ .B }}.go", []byte(""), 0664))
+	require.NoError(t, inpFS.WriteFile(
+		"config.yaml",
+		[]byte(dedent.Dedent(`
+			not-module: true
+			variables:
+			  - name: A
+			    default: same
+			  - name: B
+			    default: same
+		`)),
+		0664,
+	))
+
+	sk := NewSkeley(SkeleyConfig{
+		InputFS:    inpFS,
+		OutputPath: t.TempDir(),
+	})
+
+	_, err := sk.Plan()
+	require.EqualError(t, err, "{{ .A }}.go and {{ .B }}.go both render to same.go")
+}
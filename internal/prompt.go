@@ -0,0 +1,269 @@
+package internal
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"golang.org/x/term"
+)
+
+// Prompter asks the user for values on an input/output pair, normally stdin and stdout
+type Prompter struct {
+	in  io.Reader
+	buf *bufio.Reader
+	out io.Writer
+}
+
+func NewPrompter(in io.Reader, out io.Writer) *Prompter {
+	return &Prompter{
+		in:  in,
+		buf: bufio.NewReader(in),
+		out: out,
+	}
+}
+
+// IsTerminal reports if the reader is an interactive terminal
+func IsTerminal(r io.Reader) bool {
+	f, ok := r.(*os.File)
+	return ok && term.IsTerminal(int(f.Fd()))
+}
+
+func (p *Prompter) readLine() (string, error) {
+	line, err := p.buf.ReadString('\n')
+	if err != nil && !(errors.Is(err, io.EOF) && line != "") {
+		return "", fmt.Errorf("error reading input: %w", err)
+	}
+	return strings.TrimRight(line, "\r\n"), nil
+}
+
+func (p *Prompter) ask(label string, hint string) (string, error) {
+	if hint != "" {
+		fmt.Fprintf(p.out, "%v [%v]: ", label, hint)
+	} else {
+		fmt.Fprintf(p.out, "%v: ", label)
+	}
+	return p.readLine()
+}
+
+// String asks for a free text value, returning def if nothing is entered. validate is called on the answer, and the
+// question repeated if it returns an error
+func (p *Prompter) String(label string, def string, validate func(string) error) (string, error) {
+	for {
+		answer, err := p.ask(label, def)
+		if err != nil {
+			return "", err
+		}
+		if answer == "" {
+			answer = def
+		}
+		if err := runValidate(validate, answer); err != nil {
+			fmt.Fprintf(p.out, "invalid value: %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// Secret asks for a value without echoing it, when reading from a terminal
+func (p *Prompter) Secret(label string, validate func(string) error) (string, error) {
+	for {
+		fmt.Fprintf(p.out, "%v: ", label)
+
+		var answer string
+		// Input typed ahead is already in the buffer, and reading the terminal directly would skip it
+		if IsTerminal(p.in) && p.buf.Buffered() == 0 {
+			b, err := term.ReadPassword(int(p.in.(*os.File).Fd()))
+			fmt.Fprintln(p.out)
+			if err != nil {
+				return "", fmt.Errorf("error reading input: %w", err)
+			}
+			answer = string(b)
+		} else {
+			line, err := p.readLine()
+			if err != nil {
+				return "", err
+			}
+			answer = line
+		}
+
+		if err := runValidate(validate, answer); err != nil {
+			fmt.Fprintf(p.out, "invalid value: %v\n", err)
+			continue
+		}
+		return answer, nil
+	}
+}
+
+// YesNo asks a yes/no question, returning def if nothing is entered
+func (p *Prompter) YesNo(label string, def bool) (bool, error) {
+	hint := "y/N"
+	if def {
+		hint = "Y/n"
+	}
+
+	for {
+		answer, err := p.ask(label, hint)
+		if err != nil {
+			return false, err
+		}
+
+		switch strings.ToLower(strings.TrimSpace(answer)) {
+		case "":
+			return def, nil
+		case "y", "yes", "true":
+			return true, nil
+		case "n", "no", "false":
+			return false, nil
+		}
+		fmt.Fprintln(p.out, "please answer y or n")
+	}
+}
+
+// Choice asks for one of the given choices, by number or value, returning def if nothing is entered
+func (p *Prompter) Choice(label string, choices []string, def string) (string, error) {
+	p.listChoices(label, choices)
+
+	for {
+		answer, err := p.ask("Choose one", def)
+		if err != nil {
+			return "", err
+		}
+		answer = strings.TrimSpace(answer)
+		if answer == "" && def != "" {
+			return def, nil
+		}
+
+		if choice, ok := pickChoice(choices, answer); ok {
+			return choice, nil
+		}
+		fmt.Fprintf(p.out, "%q is not a valid choice\n", answer)
+	}
+}
+
+// MultiChoice asks for any number of the given choices as a comma separated list of numbers or values, returning def
+// if nothing is entered
+func (p *Prompter) MultiChoice(label string, choices []string, def []string) ([]string, error) {
+	p.listChoices(label, choices)
+
+	for {
+		answer, err := p.ask("Choose any, comma separated", strings.Join(def, ","))
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(answer) == "" {
+			return def, nil
+		}
+
+		selected := []string{}
+		valid := true
+		for _, item := range strings.Split(answer, ",") {
+			item = strings.TrimSpace(item)
+			if item == "" {
+				continue
+			}
+			choice, ok := pickChoice(choices, item)
+			if !ok {
+				fmt.Fprintf(p.out, "%q is not a valid choice\n", item)
+				valid = false
+				break
+			}
+			selected = append(selected, choice)
+		}
+		if valid {
+			return selected, nil
+		}
+	}
+}
+
+func (p *Prompter) listChoices(label string, choices []string) {
+	fmt.Fprintf(p.out, "%v\n", label)
+	for i, c := range choices {
+		fmt.Fprintf(p.out, "  %v) %v\n", i+1, c)
+	}
+}
+
+func pickChoice(choices []string, answer string) (string, bool) {
+	if i, err := strconv.Atoi(answer); err == nil && i >= 1 && i <= len(choices) {
+		return choices[i-1], true
+	}
+	if contains(choices, answer) {
+		return answer, true
+	}
+	return "", false
+}
+
+func runValidate(validate func(string) error, answer string) error {
+	if validate == nil {
+		return nil
+	}
+	return validate(answer)
+}
+
+// promptVariable asks for the value of a declared variable using the input suited to its type
+func promptVariable(p *Prompter, v templateVariable) (any, error) {
+	label := v.Name
+	if v.Description != "" {
+		label = fmt.Sprintf("%v (%v)", v.Name, v.Description)
+	}
+
+	def := ""
+	if v.Default != nil {
+		if coerced, err := coerceValue(v.varType(), v.Default); err == nil {
+			if list, ok := coerced.([]string); ok {
+				def = strings.Join(list, ",")
+			} else {
+				def = fmt.Sprint(coerced)
+			}
+		}
+	}
+
+	validate := func(answer string) error {
+		if answer == "" && !v.Required {
+			return nil
+		}
+		if answer == "" {
+			return fmt.Errorf("a value is required")
+		}
+		val, err := coerceValue(v.varType(), answer)
+		if err != nil {
+			return err
+		}
+		return v.validate(val)
+	}
+
+	switch v.varType() {
+	case VariableTypeBool:
+		b, _ := strconv.ParseBool(def)
+		return p.YesNo(label, b)
+	case VariableTypeList:
+		if len(v.Choices) > 0 {
+			defList := []string{}
+			if def != "" {
+				defList = strings.Split(def, ",")
+			}
+			return p.MultiChoice(label, v.Choices, defList)
+		}
+		return optional(p.String(label, def, validate))
+	default:
+		if len(v.Choices) > 0 {
+			return p.Choice(label, v.Choices, def)
+		}
+		if v.Secret {
+			return optional(p.Secret(label, validate))
+		}
+		return optional(p.String(label, def, validate))
+	}
+}
+
+// optional converts an empty answer to nil, so the variable falls back to its default or zero value
+func optional(answer string, err error) (any, error) {
+	if err != nil || answer == "" {
+		return nil, err
+	}
+	return answer, nil
+}
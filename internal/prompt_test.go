@@ -0,0 +1,283 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPrompter(t *testing.T) {
+	t.Run("string", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPrompter(strings.NewReader("api\n"), &out)
+
+		answer, err := p.String("Service", "default", nil)
+		require.NoError(t, err)
+		require.Equal(t, "api", answer)
+		require.Equal(t, "Service [default]: ", out.String())
+	})
+
+	t.Run("string default", func(t *testing.T) {
+		p := NewPrompter(strings.NewReader("\n"), &bytes.Buffer{})
+
+		answer, err := p.String("Service", "default", nil)
+		require.NoError(t, err)
+		require.Equal(t, "default", answer)
+	})
+
+	t.Run("string reprompts on invalid", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPrompter(strings.NewReader("Bad\ngood\n"), &out)
+
+		v := templateVariable{Name: "Service", Validate: "^[a-z]+$"}
+		answer, err := p.String("Service", "", func(s string) error { return v.validate(s) })
+		require.NoError(t, err)
+		require.Equal(t, "good", answer)
+		require.Contains(t, out.String(), `invalid value: "Bad" does not match ^[a-z]+$`)
+	})
+
+	t.Run("secret not from terminal", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPrompter(strings.NewReader("hunter2\n"), &out)
+
+		answer, err := p.Secret("Password", nil)
+		require.NoError(t, err)
+		require.Equal(t, "hunter2", answer)
+		require.NotContains(t, out.String(), "hunter2")
+	})
+
+	t.Run("yes no", func(t *testing.T) {
+		testData := []struct {
+			input    string
+			def      bool
+			expected bool
+		}{
+			{input: "y\n", expected: true},
+			{input: "YES\n", expected: true},
+			{input: "n\n", def: true, expected: false},
+			{input: "\n", def: true, expected: true},
+			{input: "\n", expected: false},
+			{input: "maybe\ny\n", expected: true},
+		}
+		for _, tc := range testData {
+			p := NewPrompter(strings.NewReader(tc.input), &bytes.Buffer{})
+			answer, err := p.YesNo("Docker", tc.def)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, answer, tc.input)
+		}
+	})
+
+	t.Run("choice", func(t *testing.T) {
+		var out bytes.Buffer
+		p := NewPrompter(strings.NewReader("nope\n2\n"), &out)
+
+		answer, err := p.Choice("Database", []string{"postgres", "mysql"}, "")
+		require.NoError(t, err)
+		require.Equal(t, "mysql", answer)
+		require.Contains(t, out.String(), "  1) postgres\n  2) mysql\n")
+		require.Contains(t, out.String(), `"nope" is not a valid choice`)
+	})
+
+	t.Run("choice by value and default", func(t *testing.T) {
+		p := NewPrompter(strings.NewReader("postgres\n\n"), &bytes.Buffer{})
+
+		answer, err := p.Choice("Database", []string{"postgres", "mysql"}, "mysql")
+		require.NoError(t, err)
+		require.Equal(t, "postgres", answer)
+
+		answer, err = p.Choice("Database", []string{"postgres", "mysql"}, "mysql")
+		require.NoError(t, err)
+		require.Equal(t, "mysql", answer)
+	})
+
+	t.Run("multi choice", func(t *testing.T) {
+		p := NewPrompter(strings.NewReader("1, redis\n"), &bytes.Buffer{})
+
+		answer, err := p.MultiChoice("Features", []string{"grpc", "http", "redis"}, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"grpc", "redis"}, answer)
+	})
+
+	t.Run("eof", func(t *testing.T) {
+		p := NewPrompter(strings.NewReader(""), &bytes.Buffer{})
+
+		_, err := p.String("Service", "", nil)
+		require.ErrorContains(t, err, "error reading input")
+	})
+}
+
+func TestPromptVariable(t *testing.T) {
+	testData := []struct {
+		name     string
+		variable templateVariable
+		input    string
+		expected any
+		output   string
+	}{
+		{
+			name:     "string with description and default",
+			variable: templateVariable{Name: "Service", Description: "service name", Default: "api"},
+			input:    "\n",
+			expected: "api",
+			output:   "Service (service name) [api]: ",
+		},
+		{
+			name:     "empty optional string",
+			variable: templateVariable{Name: "Service"},
+			input:    "\n",
+			expected: nil,
+		},
+		{
+			name:     "required string",
+			variable: templateVariable{Name: "Service", Required: true},
+			input:    "\nsvc\n",
+			expected: "svc",
+			output:   "Service: invalid value: a value is required\nService: ",
+		},
+		{
+			name:     "int",
+			variable: templateVariable{Name: "Port", Type: VariableTypeInt, Default: 8080},
+			input:    "eighty\n90\n",
+			expected: "90",
+		},
+		{
+			name:     "bool",
+			variable: templateVariable{Name: "UseDocker", Type: VariableTypeBool, Default: true},
+			input:    "\n",
+			expected: true,
+		},
+		{
+			name:     "choice",
+			variable: templateVariable{Name: "DB", Choices: []string{"postgres", "mysql"}},
+			input:    "1\n",
+			expected: "postgres",
+		},
+		{
+			name:     "multi choice",
+			variable: templateVariable{Name: "Features", Type: VariableTypeList, Choices: []string{"a", "b"}, Default: []any{"b"}},
+			input:    "\n",
+			expected: []string{"b"},
+		},
+		{
+			name:     "list",
+			variable: templateVariable{Name: "Owners", Type: VariableTypeList},
+			input:    "a,b\n",
+			expected: "a,b",
+		},
+		{
+			name:     "secret",
+			variable: templateVariable{Name: "Token", Secret: true},
+			input:    "s3cret\n",
+			expected: "s3cret",
+			output:   "Token: ",
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			var out bytes.Buffer
+			p := NewPrompter(strings.NewReader(tc.input), &out)
+
+			got, err := promptVariable(p, tc.variable)
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+			if tc.output != "" {
+				require.Equal(t, tc.output, out.String())
+			}
+		})
+	}
+}
+
+func TestExecutePrompt(t *testing.T) {
+	newInput := func(t *testing.T) *memfs.FS {
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/out.txt", []byte("{{ .Service }}:{{ .Port }}"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				not-module: true
+				variables:
+				  - name: Service
+				    required: true
+				  - name: Port
+				    type: int
+				    default: 8080
+			`)),
+			0664,
+		))
+		return inpFS
+	}
+
+	t.Run("prompts for missing values", func(t *testing.T) {
+		dir := t.TempDir()
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			Values: map[string]any{
+				"Port": "9090",
+			},
+			Prompter: NewPrompter(strings.NewReader("api\n"), &bytes.Buffer{}),
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "api:9090", string(content))
+	})
+
+	t.Run("no prompter fails before writing", func(t *testing.T) {
+		dir := filepath.Join(t.TempDir(), "out")
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+		})
+		require.ErrorContains(t, sk.Execute(), `missing value for required variable "Service"`)
+		require.NoDirExists(t, dir)
+	})
+
+	t.Run("no input fails without a default", func(t *testing.T) {
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/out.txt", []byte("{{ .Service }} {{ .Region }}"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				not-module: true
+				variables:
+				  - name: Service
+				    default: api
+				  - name: Region
+				  - name: Zone
+			`)),
+			0664,
+		))
+
+		dir := filepath.Join(t.TempDir(), "out")
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+			Values:     map[string]any{"Zone": "a"},
+			NoInput:    true,
+		})
+		require.EqualError(
+			t,
+			sk.Execute(),
+			"no value for Region and --no-input was given, supply it with --set, a values file or the environment",
+		)
+		require.NoDirExists(t, dir)
+
+		sk.conf.Values["Region"] = "eu"
+		require.NoError(t, sk.Execute())
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "api eu", string(content))
+	})
+}
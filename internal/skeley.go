@@ -1,41 +1,129 @@
 package internal
 
 import (
+	"bytes"
 	"errors"
 	"fmt"
-	"html/template"
+	"io"
 	"io/fs"
 	"os"
+	"path"
 	"path/filepath"
-
+	"regexp"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"unicode/utf8"
+
+	"github.com/nicjohnson145/skeley/config"
 	"github.com/rs/zerolog"
 	"golang.org/x/mod/modfile"
-	"golang.org/x/mod/semver"
+	"golang.org/x/mod/module"
 	"gopkg.in/yaml.v3"
 )
 
 type templateConfig struct {
+	// Name, Description, Tags and Maintainers describe the template for `list` and `show`
+	Name        string   `yaml:"name,omitempty"`
+	Description string   `yaml:"description,omitempty"`
+	Tags        []string `yaml:"tags,omitempty"`
+	Maintainers []string `yaml:"maintainers,omitempty"`
+
 	PreCmds   []string `yaml:"pre-cmds,omitempty"`
 	PostCmds  []string `yaml:"post-cmds,omitempty"`
 	NotModule bool     `yaml:"not-module"`
+	// EscapeHTML renders every file with html/template, not just `.html` files
+	EscapeHTML bool               `yaml:"escape-html,omitempty"`
+	Variables  []templateVariable `yaml:"variables,omitempty"`
+	// CopyOnly files are copied to the output unchanged instead of being rendered
+	CopyOnly []string `yaml:"copy-only,omitempty"`
+	// Raw is an alias of CopyOnly
+	Raw []string `yaml:"raw,omitempty"`
+	// Conditions maps glob patterns to template expressions, matching files are skipped if the expression is false
+	Conditions map[string]string `yaml:"conditions,omitempty"`
+	// Extends names a parent template, from the same source, whose files, variables and hooks this template builds on
+	Extends string `yaml:"extends,omitempty"`
+}
+
+func (c templateConfig) copyOnlyPatterns() []string {
+	return append(append([]string{}, c.CopyOnly...), c.Raw...)
+}
+
+type templateFile struct {
+	// Name is the slash separated path of the file under `files/`, and the name of its template
+	Name string
+	// Copy files are streamed to the output unchanged
+	Copy bool
+	// FS is the `files/` directory the file was found in
+	FS fs.FS
 }
 
 type moduleInfo struct {
-	Module     string
+	Module string
+	// BinaryName is the last element of the module path, ignoring any major version suffix
 	BinaryName string
 	GoVersion  string
+	// GoMinor is the minor number of GoVersion, so templates can compare versions
+	GoMinor int
+	// Toolchain is the toolchain directive, like `go1.21.1`, if there is one
+	Toolchain string
+	// Host, Owner and Repo are the elements of a module path like `github.com/owner/repo`. Host is empty if the
+	// first element isn't a domain, and Owner if there is only one element after it
+	Host  string
+	Owner string
+	Repo  string
+	// MajorVersion is the major version suffix of the module path, like `v2`, empty for v0 and v1 modules
+	MajorVersion string
+	Requires     []ModuleRequire
+	Replaces     []ModuleReplace
 }
 
-type templateVars struct {
-	Module     string
-	BinaryName string
-	GoVersion  string
+// ModuleRequire is a require directive from go.mod
+type ModuleRequire struct {
+	Path     string
+	Version  string
+	Indirect bool
+}
+
+// ModuleReplace is a replace directive from go.mod. OldVersion is empty when every version is replaced, and NewVersion
+// when the replacement is a local directory
+type ModuleReplace struct {
+	Old        string
+	OldVersion string
+	New        string
+	NewVersion string
 }
 
 type SkeleyConfig struct {
 	Logger     zerolog.Logger
 	InputFS    fs.FS
 	OutputPath string
+	// Values for declared template variables, taking precedence over defaults. Values from the environment take
+	// precedence over these
+	Values map[string]any
+	// Prompter is used to ask for any variables without a value. If nil, defaults are used instead
+	Prompter *Prompter
+	// NoInput fails on variables that would otherwise be prompted for and have no default, rather than using their zero
+	// value
+	NoInput bool
+	// SourceFS is the root of the template source InputFS came from, used to find parent templates
+	SourceFS fs.FS
+	// Template is the name of the template within SourceFS
+	Template string
+	// SourceInfo describes where the template came from, for reporting
+	SourceInfo SourceInfo
+	// OnConflict decides what happens to files that already exist in the output with different content, defaulting to
+	// overwriting them
+	OnConflict config.ConflictPolicy
+	// Version is the version of skeley, recorded in the manifest
+	Version string
+	// Module is the module path for module templates. It overrides the path from an existing go.mod, and if there
+	// isn't one, a go.mod is created for it
+	Module string
+	// GoVersion is the go directive of a created go.mod, defaulting to the Go release skeley was built with
+	GoVersion string
 }
 
 func NewSkeley(conf SkeleyConfig) *Skeley {
@@ -54,55 +142,299 @@ type Skeley struct {
 	outputPath string
 }
 
-func (s *Skeley) ListTemplates() ([]string, error) {
+// ListTemplates returns the templates in the input, directories containing `files/` or a `config.yaml`
+func (s *Skeley) ListTemplates() ([]TemplateInfo, error) {
 	entries, err := fs.ReadDir(s.inputFS, ".")
 	if err != nil {
 		return nil, fmt.Errorf("error listing directory: %w", err)
 	}
 
-	templates := []string{}
+	templates := []TemplateInfo{}
 	for _, e := range entries {
 		if !e.IsDir() {
 			continue
 		}
 
-		templates = append(templates, e.Name())
+		ok, err := isTemplateDir(s.inputFS, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			s.log.Debug().Str("dir", e.Name()).Msg("not a template, skipping")
+			continue
+		}
+
+		tmplFS, err := fs.Sub(s.inputFS, e.Name())
+		if err != nil {
+			return nil, err
+		}
+		conf, err := readTemplateConfig(tmplFS)
+		if err != nil {
+			s.log.Warn().Err(err).Str("template", e.Name()).Msg("unable to read template metadata")
+			conf = templateConfig{}
+		}
+
+		templates = append(templates, conf.info(e.Name()))
 	}
 
 	return templates, nil
 }
 
 func (s *Skeley) Execute() error {
-	s.log.Debug().Msg("attempting to read template config")
-	config, err := s.getTemplateConfig()
+	tmpl, values, err := s.prepare()
 	if err != nil {
 		return err
 	}
 
+	// Plan before the pre-cmds run, so errors and conflicts are reported before anything touches the output directory.
+	// Pre-cmds can create go.mod, so a missing one only fails here if there are none
+	plan, err := s.buildPlan(tmpl, values)
+	if err != nil && !(errors.Is(err, fs.ErrNotExist) && len(tmpl.conf.PreCmds) > 0) {
+		return err
+	}
+
+	if err := os.MkdirAll(s.outputPath, 0775); err != nil {
+		s.log.Err(err).Msg("making output directory")
+		return err
+	}
+
+	hooks := newHookRunner(s.log, s.outputPath)
+
+	s.log.Debug().Msg("running pre-cmds")
+	if err := hooks.run("pre-cmds", tmpl.conf.PreCmds); err != nil {
+		return err
+	}
+
+	if len(tmpl.conf.PreCmds) > 0 {
+		// Pre-cmds can change the output directory, so plan again against what they left
+		plan, err = s.buildPlan(tmpl, values)
+		if err != nil {
+			return err
+		}
+	}
+
+	if err := s.applyPlan(plan); err != nil {
+		return err
+	}
+
+	s.log.Debug().Msg("running post-cmds")
+	if err := hooks.run("post-cmds", tmpl.conf.PostCmds); err != nil {
+		return err
+	}
+
+	// Files that were skipped, or that the user declined to overwrite, don't hold the template's content
+	written := []PlannedFile{}
+	for _, f := range plan.Files {
+		if f.Action != PlanActionSkip {
+			written = append(written, f)
+		}
+	}
+	if err := s.writeManifest(tmpl, values, written); err != nil {
+		return err
+	}
+
+	summary := s.log.Info().Int("files", len(plan.Files))
+	if src := plan.Source; src != nil {
+		summary = summary.Str("source", src.Location).Str("ref", src.Ref).Str("commit", src.Commit)
+	}
+	summary.Msg("rendered template")
+
+	return nil
+}
+
+func (s *Skeley) sourceInfo() *SourceInfo {
+	if s.conf.SourceInfo == (SourceInfo{}) {
+		return nil
+	}
+	info := s.conf.SourceInfo
+	return &info
+}
+
+// Plan runs the template without writing any files or running any commands, reporting what Execute would do
+func (s *Skeley) Plan() (*Plan, error) {
+	tmpl, values, err := s.prepare()
+	if err != nil {
+		return nil, err
+	}
+
+	return s.buildPlan(tmpl, values)
+}
+
+// prepare reads the template config and resolves variables. This happens before anything touches the output
+// directory, so missing values fail fast
+func (s *Skeley) prepare() (loadedTemplate, map[string]any, error) {
+	s.log.Debug().Msg("attempting to read template config")
+	tmpl, err := s.loadTemplate()
+	if err != nil {
+		return loadedTemplate{}, nil, err
+	}
+
+	values, err := s.resolveValues(tmpl.conf.Variables)
+	if err != nil {
+		return loadedTemplate{}, nil, err
+	}
+
+	return tmpl, values, nil
+}
+
+// errConflicts is returned by buildPlan when the conflict policy is error and existing files would change
+var errConflicts = errors.New("files already exist in the output directory")
+
+func (s *Skeley) buildPlan(tmpl loadedTemplate, values map[string]any) (*Plan, error) {
+	tmplConf := tmpl.conf
+
 	vars := templateVars{}
-	if !config.NotModule {
+	if !tmplConf.NotModule {
 		s.log.Debug().Msg("template is configured as go module, attempting to parse go.mod")
 		mod, err := s.parseModule()
 		if err != nil {
-			return err
+			return nil, err
 		}
-		vars.Module = mod.Module
-		vars.BinaryName = mod.BinaryName
-		vars.GoVersion = mod.GoVersion
+		vars = templateVars(mod)
 	}
 
-	filesFS, err := fs.Sub(s.inputFS, "files")
+	renderCtx := renderContext(vars, values)
+
+	funcMap := templateFuncs()
+
+	root, files, err := s.findAndParseTemplates(tmpl.layers, funcMap, tmplConf)
 	if err != nil {
-		return fmt.Errorf("error creating subFS: %w", err)
+		return nil, err
 	}
 
-	root, files, err := s.findAndParseTemplates(filesFS, template.FuncMap{})
+	excluded, err := evaluateConditions(tmplConf.Conditions, renderCtx, funcMap)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	plan := &Plan{
+		Source:   s.sourceInfo(),
+		Files:    []PlannedFile{},
+		PreCmds:  tmplConf.PreCmds,
+		PostCmds: tmplConf.PostCmds,
+	}
+	seen := map[string]string{}
+	conflicts := []string{}
+
+	if !tmplConf.NotModule {
+		goMod, err := s.plannedGoMod()
+		if err != nil {
+			return nil, err
+		}
+		if goMod != nil {
+			s.log.Debug().Str("module", s.conf.Module).Msg("go.mod is missing, planning to create it")
+			plan.Files = append(plan.Files, *goMod)
+			seen[goMod.Path] = fmt.Sprintf("the go.mod for --%v", config.Module)
+		}
 	}
 
 	for _, fl := range files {
-		if err := s.renderFile(root, fl, vars); err != nil {
+		skip, err := matchAnyGlob(excluded, fl.Name)
+		if err != nil {
+			return nil, err
+		}
+		if skip {
+			s.log.Debug().Str("path", fl.Name).Msg("condition is false, skipping")
+			continue
+		}
+
+		output, ok, err := renderPath(fl.Name, renderCtx, funcMap)
+		if err != nil {
+			s.log.Err(err).Str("path", fl.Name).Msg("rendering path")
+			return nil, err
+		}
+		if !ok {
+			s.log.Debug().Str("path", fl.Name).Msg("path renders empty, skipping")
+			continue
+		}
+		if other, ok := seen[output]; ok {
+			return nil, fmt.Errorf("%v and %v both render to %v", other, fl.Name, output)
+		}
+		seen[output] = fl.Name
+
+		planned := PlannedFile{
+			Path:     output,
+			source:   fl.Name,
+			sourceFS: fl.FS,
+			copy:     fl.Copy,
+		}
+		if !fl.Copy {
+			var buf bytes.Buffer
+			if err := root.execute(&buf, fl.Name, renderCtx); err != nil {
+				s.log.Err(err).Str("path", fl.Name).Msg("executing template")
+				return nil, err
+			}
+			planned.content = buf.Bytes()
+		}
+
+		content, err := planned.open()
+		if err != nil {
+			return nil, err
+		}
+		planned.Action, err = planAction(s.outputFile(output), content)
+		content.Close()
+		if err != nil {
+			s.log.Err(err).Str("path", output).Msg("comparing with existing file")
+			return nil, err
+		}
+
+		if planned.Action == PlanActionOverwrite {
+			switch s.conf.OnConflict {
+			case config.ConflictPolicySkip:
+				planned.Action = PlanActionSkip
+			case config.ConflictPolicyBackup:
+				planned.Action = PlanActionBackup
+			case config.ConflictPolicyError:
+				conflicts = append(conflicts, output)
+			}
+		}
+
+		plan.Files = append(plan.Files, planned)
+	}
+
+	if len(conflicts) > 0 {
+		return nil, fmt.Errorf("%w: %v", errConflicts, strings.Join(conflicts, ", "))
+	}
+
+	return plan, nil
+}
+
+func (s *Skeley) applyPlan(plan *Plan) error {
+	if s.conf.OnConflict == config.ConflictPolicyPrompt && s.conf.Prompter == nil {
+		for _, f := range plan.Files {
+			if f.Action == PlanActionOverwrite {
+				return fmt.Errorf("%v already exists and cannot prompt to overwrite it without a terminal", f.Path)
+			}
+		}
+	}
+
+	for i, f := range plan.Files {
+		switch f.Action {
+		case PlanActionUnchanged:
+			s.log.Debug().Str("path", f.Path).Msg("content unchanged, not writing")
+			continue
+		case PlanActionSkip:
+			s.log.Info().Str("path", f.Path).Msg("file already exists, skipping")
+			continue
+		case PlanActionBackup:
+			if err := s.backupFile(f.Path); err != nil {
+				return err
+			}
+		case PlanActionOverwrite:
+			if s.conf.OnConflict == config.ConflictPolicyPrompt {
+				overwrite, err := s.promptOverwrite(f)
+				if err != nil {
+					return err
+				}
+				if !overwrite {
+					s.log.Info().Str("path", f.Path).Msg("keeping existing file")
+					plan.Files[i].Action = PlanActionSkip
+					continue
+				}
+			}
+		}
+
+		if err := s.writeFile(f); err != nil {
 			return err
 		}
 	}
@@ -110,8 +442,137 @@ func (s *Skeley) Execute() error {
 	return nil
 }
 
+// backupFile copies an existing output file to `<file>.orig`, or `<file>.orig.N` if earlier backups exist
+func (s *Skeley) backupFile(path string) error {
+	output := s.outputFile(path)
+
+	content, err := os.ReadFile(output)
+	if err != nil {
+		s.log.Err(err).Str("path", path).Msg("reading file to back up")
+		return err
+	}
+
+	info, err := os.Stat(output)
+	if err != nil {
+		return err
+	}
+
+	backup := output + ".orig"
+	for i := 1; ; i++ {
+		out, err := os.OpenFile(backup, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+		if errors.Is(err, fs.ErrExist) {
+			backup = fmt.Sprintf("%v.orig.%v", output, i)
+			continue
+		}
+		if err != nil {
+			s.log.Err(err).Str("path", path).Msg("creating backup")
+			return err
+		}
+
+		s.log.Info().Str("path", path).Str("backup", filepath.Base(backup)).Msg("backing up existing file")
+		_, err = out.Write(content)
+		if cerr := out.Close(); err == nil {
+			err = cerr
+		}
+		if err != nil {
+			s.log.Err(err).Str("path", path).Msg("writing backup")
+			return err
+		}
+
+		return nil
+	}
+}
+
+// promptOverwrite shows the difference between an existing output file and its new content, and asks if it should be
+// overwritten
+func (s *Skeley) promptOverwrite(f PlannedFile) (bool, error) {
+	existing, err := os.ReadFile(s.outputFile(f.Path))
+	if err != nil {
+		return false, err
+	}
+
+	src, err := f.open()
+	if err != nil {
+		return false, err
+	}
+	defer src.Close()
+	updated, err := io.ReadAll(src)
+	if err != nil {
+		return false, err
+	}
+
+	diff, err := unifiedDiff(f.Path, f.Path+" (template)", existing, updated)
+	if err != nil {
+		return false, err
+	}
+
+	fmt.Fprint(s.conf.Prompter.out, diff)
+	return s.conf.Prompter.YesNo(fmt.Sprintf("Overwrite %v?", f.Path), false)
+}
+
+func (s *Skeley) resolveValues(decls []templateVariable) (map[string]any, error) {
+	declared := map[string]bool{}
+	for _, d := range decls {
+		declared[d.Name] = true
+	}
+
+	supplied := map[string]any{}
+	for k, v := range s.conf.Values {
+		if !declared[k] {
+			s.log.Warn().Str("variable", k).Msg("ignoring value for undeclared variable")
+			continue
+		}
+		supplied[k] = v
+	}
+	for k, v := range envValues(decls) {
+		supplied[k] = v
+	}
+
+	if s.conf.NoInput {
+		missing := []string{}
+		for _, d := range decls {
+			if _, ok := supplied[d.Name]; !ok && d.Default == nil {
+				missing = append(missing, d.Name)
+			}
+		}
+		if len(missing) > 0 {
+			return nil, fmt.Errorf(
+				"no value for %v and --%v was given, supply it with --%v, a values file or the environment",
+				strings.Join(missing, ", "),
+				config.NoInput,
+				config.Set,
+			)
+		}
+	}
+
+	if s.conf.Prompter != nil {
+		for _, d := range decls {
+			if _, ok := supplied[d.Name]; ok {
+				continue
+			}
+			val, err := promptVariable(s.conf.Prompter, d)
+			if err != nil {
+				return nil, err
+			}
+			supplied[d.Name] = val
+		}
+	}
+
+	return resolveVariables(decls, supplied)
+}
+
 func (s *Skeley) getTemplateConfig() (templateConfig, error) {
-	content, err := fs.ReadFile(s.inputFS, "config.yaml")
+	conf, err := readTemplateConfig(s.inputFS)
+	if err != nil {
+		s.log.Err(err).Msg("reading template config")
+		return templateConfig{}, err
+	}
+
+	return conf, nil
+}
+
+func readTemplateConfig(fsys fs.FS) (templateConfig, error) {
+	content, err := fs.ReadFile(fsys, "config.yaml")
 	if err != nil {
 		if errors.Is(err, fs.ErrNotExist) {
 			return templateConfig{}, nil
@@ -121,8 +582,7 @@ func (s *Skeley) getTemplateConfig() (templateConfig, error) {
 
 	var conf templateConfig
 	if err := yaml.Unmarshal(content, &conf); err != nil {
-		s.log.Err(err).Msg("error unmarshalling")
-		return templateConfig{}, err
+		return templateConfig{}, fmt.Errorf("error parsing config: %w", err)
 	}
 
 	return conf, nil
@@ -130,83 +590,332 @@ func (s *Skeley) getTemplateConfig() (templateConfig, error) {
 
 func (s *Skeley) parseModule() (moduleInfo, error) {
 	modBytes, err := os.ReadFile(s.getGoModPath())
+	if errors.Is(err, fs.ErrNotExist) && s.conf.Module != "" {
+		// Describe the go.mod the plan creates, so planning doesn't need it to exist yet
+		modBytes, err = s.newGoMod()
+	}
 	if err != nil {
 		s.log.Err(err).Msg("error reading `go.mod`")
+		if errors.Is(err, fs.ErrNotExist) {
+			return moduleInfo{}, fmt.Errorf("no go.mod in %v, run `go mod init` or use --%v: %w", s.outputPath, config.Module, err)
+		}
 		return moduleInfo{}, err
 	}
 
 	fl, err := modfile.Parse("go.mod", modBytes, func(path, version string) (string, error) {
-		return semver.Canonical(version), nil
+		return module.CanonicalVersion(version), nil
 	})
 	if err != nil {
 		s.log.Err(err).Msg("error parsing `go.mod`")
 		return moduleInfo{}, err
 	}
+	if fl.Module == nil {
+		return moduleInfo{}, fmt.Errorf("go.mod has no module directive")
+	}
+
+	mod := newModuleInfo(fl)
+	if s.conf.Module != "" {
+		mod.setPath(s.conf.Module)
+	}
+
+	return mod, nil
+}
+
+// newModuleInfo describes a parsed go.mod, which must have a module directive
+func newModuleInfo(fl *modfile.File) moduleInfo {
+	mod := moduleInfo{
+		Requires: []ModuleRequire{},
+		Replaces: []ModuleReplace{},
+	}
+	mod.setPath(fl.Module.Mod.Path)
+
+	if fl.Go != nil {
+		mod.GoVersion = fl.Go.Version
+		if m := goMinorRE.FindStringSubmatch(fl.Go.Version); m != nil {
+			mod.GoMinor, _ = strconv.Atoi(m[1])
+		}
+	}
+	if fl.Toolchain != nil {
+		mod.Toolchain = fl.Toolchain.Name
+	}
+
+	for _, r := range fl.Require {
+		mod.Requires = append(mod.Requires, ModuleRequire{
+			Path:     r.Mod.Path,
+			Version:  r.Mod.Version,
+			Indirect: r.Indirect,
+		})
+	}
+	for _, r := range fl.Replace {
+		mod.Replaces = append(mod.Replaces, ModuleReplace{
+			Old:        r.Old.Path,
+			OldVersion: r.Old.Version,
+			New:        r.New.Path,
+			NewVersion: r.New.Version,
+		})
+	}
+
+	return mod
+}
+
+var goMinorRE = regexp.MustCompile(`^\d+\.(\d+)`)
+
+// setPath sets the module path, along with everything derived from it
+func (m *moduleInfo) setPath(modulePath string) {
+	m.Module = modulePath
+
+	prefix, pathMajor, ok := module.SplitPathVersion(modulePath)
+	if !ok {
+		prefix, pathMajor = modulePath, ""
+	}
+	// Suffixes are `/v2`, or `.v2` for gopkg.in
+	m.MajorVersion = strings.TrimLeft(pathMajor, "/.")
+	m.BinaryName = path.Base(prefix)
+
+	m.Host, m.Owner, m.Repo = "", "", ""
+	elems := strings.Split(prefix, "/")
+	if strings.Contains(elems[0], ".") {
+		m.Host = elems[0]
+		elems = elems[1:]
+	}
+	switch len(elems) {
+	case 0:
+	case 1:
+		m.Repo = elems[0]
+	default:
+		m.Owner = elems[0]
+		m.Repo = elems[1]
+	}
+}
+
+// plannedGoMod returns a go.mod to create for the configured module path, as `go mod init` would, or nil if there is no
+// module path or the output directory already has a go.mod
+func (s *Skeley) plannedGoMod() (*PlannedFile, error) {
+	if s.conf.Module == "" {
+		return nil, nil
+	}
+	if _, err := os.Stat(s.getGoModPath()); err == nil {
+		return nil, nil
+	} else if !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
 
-	return moduleInfo{
-		Module:     fl.Module.Mod.Path,
-		GoVersion:  fl.Go.Version,
-		BinaryName: filepath.Base(fl.Module.Mod.Path),
+	content, err := s.newGoMod()
+	if err != nil {
+		return nil, err
+	}
+
+	return &PlannedFile{
+		Path:    "go.mod",
+		Action:  PlanActionCreate,
+		content: content,
 	}, nil
 }
 
+// newGoMod formats a go.mod for the configured module path and Go version
+func (s *Skeley) newGoMod() ([]byte, error) {
+	if err := module.CheckImportPath(s.conf.Module); err != nil {
+		return nil, fmt.Errorf("invalid module path: %w", err)
+	}
+
+	goVersion := s.conf.GoVersion
+	if goVersion == "" {
+		goVersion = defaultGoVersion()
+	}
+
+	fl := &modfile.File{}
+	if err := fl.AddModuleStmt(s.conf.Module); err != nil {
+		return nil, err
+	}
+	if err := fl.AddGoStmt(goVersion); err != nil {
+		return nil, fmt.Errorf("invalid go version: %w", err)
+	}
+
+	return modfile.Format(fl.Syntax), nil
+}
+
+// fallbackGoVersion is used for new go.mod files when the Go release skeley was built with can't be determined
+const fallbackGoVersion = "1.20"
+
+var goReleaseRE = regexp.MustCompile(`^go(\d+\.\d+)`)
+
+// defaultGoVersion is the language version of the Go release skeley was built with
+func defaultGoVersion() string {
+	if m := goReleaseRE.FindStringSubmatch(runtime.Version()); m != nil {
+		return m[1]
+	}
+	return fallbackGoVersion
+}
+
 func (s *Skeley) getGoModPath() string {
 	return filepath.Join(s.outputPath, "go.mod")
 }
 
-func (s *Skeley) findAndParseTemplates(fsys fs.FS, funcMap template.FuncMap) (*template.Template, []string, error) {
-	root := template.New("")
+// findAndParseTemplates parses the partials and files of each template layer in order, returning the files that
+// should be output. A file in a later layer replaces the same file from an earlier one, unless it only contains
+// `{{ define }}` blocks, in which case those override the matching `{{ block }}` definitions in the earlier file
+func (s *Skeley) findAndParseTemplates(layers []fs.FS, funcMap template.FuncMap, conf templateConfig) (*templateSet, []templateFile, error) {
+	root := newTemplateSet(funcMap, conf.EscapeHTML)
+	copyPatterns := conf.copyOnlyPatterns()
 
-	filenames := []string{}
+	byName := map[string]templateFile{}
 
-	err := fs.WalkDir(fsys, ".", func(path string, info fs.DirEntry, e1 error) error {
-		if e1 != nil {
-			return fmt.Errorf("error from walk function: %w", e1)
+	for _, tmplFS := range layers {
+		if err := s.parsePartials(tmplFS, root); err != nil {
+			return nil, nil, err
 		}
-		if !info.IsDir() {
+
+		fsys, err := fs.Sub(tmplFS, "files")
+		if err != nil {
+			return nil, nil, fmt.Errorf("error creating subFS: %w", err)
+		}
+
+		err = fs.WalkDir(fsys, ".", func(path string, info fs.DirEntry, e1 error) error {
+			if e1 != nil {
+				if path == "." && errors.Is(e1, fs.ErrNotExist) && len(layers) > 1 {
+					// Templates extending another don't need to have files of their own
+					return fs.SkipDir
+				}
+				return fmt.Errorf("error from walk function: %w", e1)
+			}
+			if info.IsDir() {
+				return nil
+			}
+
+			copyOnly, e2 := matchAnyGlob(copyPatterns, path)
+			if e2 != nil {
+				s.log.Err(e2).Msg("matching copy-only patterns")
+				return e2
+			}
+			if copyOnly {
+				s.log.Debug().Str("path", path).Msg("file is copy-only, not parsing")
+				byName[path] = templateFile{Name: path, Copy: true, FS: fsys}
+				return nil
+			}
+
 			b, e2 := fs.ReadFile(fsys, path)
 			if e2 != nil {
 				s.log.Err(e2).Str("path", path).Msg("reading template file")
 				return e2
 			}
 
-			filenames = append(filenames, path)
-			t := root.New(path).Funcs(funcMap)
-			_, e2 = t.Parse(string(b))
-			if e2 != nil {
+			if isBinary(b) {
+				s.log.Debug().Str("path", path).Msg("file is binary, not parsing")
+				byName[path] = templateFile{Name: path, Copy: true, FS: fsys}
+				return nil
+			}
+
+			byName[path] = templateFile{Name: path, FS: fsys}
+			if e2 = root.parse(path, string(b)); e2 != nil {
 				s.log.Err(e2).Str("path", path).Msg("parsing template file")
 				return e2
 			}
+
+			return nil
+		})
+		if err != nil {
+			return nil, nil, err
 		}
+	}
 
-		return nil
-	})
+	names := make([]string, 0, len(byName))
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := make([]templateFile, 0, len(names))
+	for _, name := range names {
+		files = append(files, byName[name])
+	}
+
+	return root, files, nil
+}
+
+// parsePartials adds every file under `partials/` to the template set, named by its path without the extension, so
+// `partials/license-header.tmpl` can be used with `{{ template "license-header" . }}`
+func (s *Skeley) parsePartials(tmplFS fs.FS, root *templateSet) error {
+	if _, err := fs.Stat(tmplFS, "partials"); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("error checking for partials: %w", err)
+	}
+
+	partialsFS, err := fs.Sub(tmplFS, "partials")
 	if err != nil {
-		return nil, nil, err
+		return fmt.Errorf("error creating subFS: %w", err)
 	}
 
-	return root, filenames, nil
+	return fs.WalkDir(partialsFS, ".", func(path string, info fs.DirEntry, e1 error) error {
+		if e1 != nil {
+			return fmt.Errorf("error from walk function: %w", e1)
+		}
+		if info.IsDir() {
+			return nil
+		}
+
+		b, e2 := fs.ReadFile(partialsFS, path)
+		if e2 != nil {
+			s.log.Err(e2).Str("path", path).Msg("reading partial")
+			return e2
+		}
+
+		name := strings.TrimSuffix(path, filepath.Ext(path))
+		s.log.Debug().Str("path", path).Str("name", name).Msg("parsing partial")
+		if e2 = root.parsePartial(name, string(b)); e2 != nil {
+			s.log.Err(e2).Str("path", path).Msg("parsing partial")
+			return e2
+		}
+
+		return nil
+	})
 }
 
-func (s *Skeley) renderFile(tmpl *template.Template, name string, vars templateVars) error {
-	output := filepath.Join(s.outputPath, name)
+func (s *Skeley) writeFile(f PlannedFile) error {
+	output := s.outputFile(f.Path)
 
 	if err := os.MkdirAll(filepath.Dir(output), 0775); err != nil {
 		s.log.Err(err).Msg("making containing directory")
 		return err
 	}
 
-	f, err := os.OpenFile(output, os.O_RDWR|os.O_CREATE|os.O_TRUNC, 0664)
+	src, err := f.open()
+	if err != nil {
+		s.log.Err(err).Msg("opening source file")
+		return err
+	}
+	defer src.Close()
+
+	perm := fs.FileMode(0664)
+	if f.copy {
+		// Keep the mode of copied files, so things like wrapper scripts stay executable
+		if info, err := fs.Stat(f.sourceFS, f.source); err == nil && info.Mode().Perm() != 0 {
+			perm = info.Mode().Perm()
+		}
+	}
+
+	out, err := os.OpenFile(output, os.O_RDWR|os.O_CREATE|os.O_TRUNC, perm)
 	if err != nil {
 		s.log.Err(err).Msg("opening target file")
 		return err
 	}
-	defer f.Close()
+	defer out.Close()
 
-	if err := tmpl.ExecuteTemplate(f, name, vars); err != nil {
-		s.log.Err(err).Msg("executing template")
+	if _, err := io.Copy(out, src); err != nil {
+		s.log.Err(err).Msg("writing file")
 		return err
 	}
 
 	return nil
 }
+
+// isBinary uses the same heuristic as git, a file is binary if it contains a NUL byte near the start. Files that
+// aren't valid UTF-8 are also treated as binary
+func isBinary(content []byte) bool {
+	head := content
+	if len(head) > 8000 {
+		head = head[:8000]
+	}
+	return bytes.IndexByte(head, 0) != -1 || !utf8.Valid(content)
+}
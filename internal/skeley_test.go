@@ -1,12 +1,11 @@
 package internal
 
 import (
-	"html/template"
 	"io/fs"
 	"os"
 	"path/filepath"
-	"sort"
 	"testing"
+	"text/template"
 
 	"github.com/lithammer/dedent"
 	"github.com/psanford/memfs"
@@ -16,8 +15,10 @@ import (
 func TestListTemplates(t *testing.T) {
 	t.Run("smokes", func(t *testing.T) {
 		inp := memfs.New()
-		require.NoError(t, inp.MkdirAll("template1", 0775))
+		require.NoError(t, inp.MkdirAll("template1/files", 0775))
 		require.NoError(t, inp.MkdirAll("template2", 0775))
+		require.NoError(t, inp.WriteFile("template2/config.yaml", []byte("not-module: true\n"), 0664))
+		require.NoError(t, inp.MkdirAll("not-a-template/docs", 0775))
 		require.NoError(t, inp.WriteFile("some-file", []byte("content"), 0664))
 
 		sk := NewSkeley(SkeleyConfig{
@@ -27,12 +28,11 @@ func TestListTemplates(t *testing.T) {
 		templates, err := sk.ListTemplates()
 		require.NoError(t, err)
 
-		sort.Strings(templates)
 		require.Equal(
 			t,
-			[]string{
-				"template1",
-				"template2",
+			[]TemplateInfo{
+				{Template: "template1"},
+				{Template: "template2"},
 			},
 			templates,
 		)
@@ -83,6 +83,7 @@ func TestParseModule(t *testing.T) {
 	testData := []struct {
 		name     string
 		mod      string
+		module   string
 		expected moduleInfo
 	}{
 		{
@@ -95,7 +96,13 @@ func TestParseModule(t *testing.T) {
 			expected: moduleInfo{
 				Module:     "github.com/nicjohnson145/skeley",
 				GoVersion:  "1.20",
+				GoMinor:    20,
 				BinaryName: "skeley",
+				Host:       "github.com",
+				Owner:      "nicjohnson145",
+				Repo:       "skeley",
+				Requires:   []ModuleRequire{},
+				Replaces:   []ModuleReplace{},
 			},
 		},
 		{
@@ -108,7 +115,11 @@ func TestParseModule(t *testing.T) {
 			expected: moduleInfo{
 				Module:     "skeley",
 				GoVersion:  "1.20",
+				GoMinor:    20,
 				BinaryName: "skeley",
+				Repo:       "skeley",
+				Requires:   []ModuleRequire{},
+				Replaces:   []ModuleReplace{},
 			},
 		},
 		{
@@ -121,7 +132,91 @@ func TestParseModule(t *testing.T) {
 			expected: moduleInfo{
 				Module:     "skeley",
 				GoVersion:  "1.21.1",
+				GoMinor:    21,
 				BinaryName: "skeley",
+				Repo:       "skeley",
+				Requires:   []ModuleRequire{},
+				Replaces:   []ModuleReplace{},
+			},
+		},
+		{
+			name: "full",
+			mod: `
+				module github.com/nicjohnson145/skeley/v2
+
+				go 1.21.1
+
+				toolchain go1.21.4
+
+				require (
+					github.com/spf13/cobra v1.6.1
+					github.com/example/legacy v2.0.0+incompatible
+					golang.org/x/sys v0.12.0 // indirect
+				)
+
+				replace github.com/spf13/cobra => ../cobra
+
+				replace golang.org/x/sys v0.12.0 => golang.org/x/sys v0.13.0
+			`,
+			expected: moduleInfo{
+				Module:       "github.com/nicjohnson145/skeley/v2",
+				GoVersion:    "1.21.1",
+				GoMinor:      21,
+				Toolchain:    "go1.21.4",
+				BinaryName:   "skeley",
+				Host:         "github.com",
+				Owner:        "nicjohnson145",
+				Repo:         "skeley",
+				MajorVersion: "v2",
+				Requires: []ModuleRequire{
+					{Path: "github.com/spf13/cobra", Version: "v1.6.1"},
+					{Path: "github.com/example/legacy", Version: "v2.0.0+incompatible"},
+					{Path: "golang.org/x/sys", Version: "v0.12.0", Indirect: true},
+				},
+				Replaces: []ModuleReplace{
+					{Old: "github.com/spf13/cobra", New: "../cobra"},
+					{Old: "golang.org/x/sys", OldVersion: "v0.12.0", New: "golang.org/x/sys", NewVersion: "v0.13.0"},
+				},
+			},
+		},
+		{
+			name: "gopkg.in",
+			mod: `
+				module gopkg.in/yaml.v3
+
+				go 1.20
+			`,
+			expected: moduleInfo{
+				Module:       "gopkg.in/yaml.v3",
+				GoVersion:    "1.20",
+				GoMinor:      20,
+				BinaryName:   "yaml",
+				Host:         "gopkg.in",
+				Repo:         "yaml",
+				MajorVersion: "v3",
+				Requires:     []ModuleRequire{},
+				Replaces:     []ModuleReplace{},
+			},
+		},
+		{
+			name: "module override",
+			mod: `
+				module skeley
+
+				go 1.20
+			`,
+			module: "github.com/nicjohnson145/other/v3",
+			expected: moduleInfo{
+				Module:       "github.com/nicjohnson145/other/v3",
+				GoVersion:    "1.20",
+				GoMinor:      20,
+				BinaryName:   "other",
+				Host:         "github.com",
+				Owner:        "nicjohnson145",
+				Repo:         "other",
+				MajorVersion: "v3",
+				Requires:     []ModuleRequire{},
+				Replaces:     []ModuleReplace{},
 			},
 		},
 	}
@@ -136,6 +231,7 @@ func TestParseModule(t *testing.T) {
 
 			sk := NewSkeley(SkeleyConfig{
 				OutputPath: dir,
+				Module:     tc.module,
 			})
 
 			info, err := sk.parseModule()
@@ -143,15 +239,79 @@ func TestParseModule(t *testing.T) {
 			require.Equal(t, tc.expected, info)
 		})
 	}
+
+	t.Run("missing", func(t *testing.T) {
+		dir := t.TempDir()
+		sk := NewSkeley(SkeleyConfig{
+			OutputPath: dir,
+		})
+
+		_, err := sk.parseModule()
+		require.ErrorContains(t, err, "no go.mod in "+dir+", run `go mod init` or use --module")
+	})
+
+	t.Run("missing with module", func(t *testing.T) {
+		dir := t.TempDir()
+		sk := NewSkeley(SkeleyConfig{
+			OutputPath: dir,
+			Module:     "github.com/nicjohnson145/skeley",
+			GoVersion:  "1.21.1",
+		})
+
+		info, err := sk.parseModule()
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			moduleInfo{
+				Module:     "github.com/nicjohnson145/skeley",
+				GoVersion:  "1.21.1",
+				GoMinor:    21,
+				BinaryName: "skeley",
+				Host:       "github.com",
+				Owner:      "nicjohnson145",
+				Repo:       "skeley",
+				Requires:   []ModuleRequire{},
+				Replaces:   []ModuleReplace{},
+			},
+			info,
+		)
+		// Nothing is written until executing
+		require.NoFileExists(t, filepath.Join(dir, "go.mod"))
+	})
+
+	t.Run("invalid module", func(t *testing.T) {
+		sk := NewSkeley(SkeleyConfig{
+			OutputPath: t.TempDir(),
+			Module:     "not a module",
+		})
+
+		_, err := sk.parseModule()
+		require.ErrorContains(t, err, "invalid module path")
+	})
+
+	t.Run("invalid go version", func(t *testing.T) {
+		sk := NewSkeley(SkeleyConfig{
+			OutputPath: t.TempDir(),
+			Module:     "skeley",
+			GoVersion:  "latest",
+		})
+
+		_, err := sk.parseModule()
+		require.ErrorContains(t, err, "invalid go version")
+	})
+
+	t.Run("default go version", func(t *testing.T) {
+		require.Regexp(t, `^\d+\.\d+$`, defaultGoVersion())
+	})
 }
 
 func TestFindAndParseTemplates(t *testing.T) {
 	t.Run("smokes", func(t *testing.T) {
 		sk := NewSkeley(SkeleyConfig{})
 
-		inpFS := os.DirFS("./testdata/simple-module/input/files")
+		inpFS := os.DirFS("./testdata/simple-module/input")
 
-		_, files, err := sk.findAndParseTemplates(inpFS, template.FuncMap{})
+		_, files, err := sk.findAndParseTemplates([]fs.FS{inpFS}, template.FuncMap{}, templateConfig{})
 		require.NoError(t, err)
 		require.NotEmpty(t, files)
 	})
@@ -176,10 +336,31 @@ func TestExecute(t *testing.T) {
 		})
 
 		require.NoError(t, sk.Execute())
+		// The manifest is covered by TestManifest
+		require.NoError(t, os.Remove(filepath.Join(dir, ManifestFile)))
 
 		// Everything in the output directory matches the expected (no extra files)
 		fsEqual(t, destFS, expectedFS)
 		// Everything in the expected directory matches the actual (no missing files)
 		fsEqual(t, expectedFS, destFS)
 	})
+
+	t.Run("creates go.mod", func(t *testing.T) {
+		dir := t.TempDir()
+		destFS := os.DirFS(dir)
+		expectedFS := os.DirFS("./testdata/simple-module/output")
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    os.DirFS("./testdata/simple-module/input"),
+			OutputPath: dir,
+			Module:     "github.com/nicjohnson145/foo_module",
+			GoVersion:  "1.20",
+		})
+
+		require.NoError(t, sk.Execute())
+		require.NoError(t, os.Remove(filepath.Join(dir, ManifestFile)))
+
+		fsEqual(t, destFS, expectedFS)
+		fsEqual(t, expectedFS, destFS)
+	})
 }
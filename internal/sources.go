@@ -0,0 +1,333 @@
+package internal
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"gopkg.in/yaml.v3"
+)
+
+// UserConfig is the user's skeley config file
+type UserConfig struct {
+	// Sources are searched in order for templates addressed without a source
+	Sources []SourceConfig `yaml:"sources"`
+}
+
+// LoadUserConfig reads the user config at path. A missing file is an empty config
+func LoadUserConfig(path string) (*UserConfig, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return &UserConfig{}, nil
+		}
+		return nil, fmt.Errorf("error reading user config: %w", err)
+	}
+
+	var conf UserConfig
+	if err := yaml.Unmarshal(content, &conf); err != nil {
+		return nil, fmt.Errorf("error parsing user config %v: %w", path, err)
+	}
+
+	seen := map[string]bool{}
+	for i := range conf.Sources {
+		src := &conf.Sources[i]
+		switch {
+		case src.Name == "":
+			return nil, fmt.Errorf("source %v in %v has no name", i+1, path)
+		case strings.Contains(src.Name, "/"):
+			return nil, fmt.Errorf("source name %q cannot contain /", src.Name)
+		case seen[src.Name]:
+			return nil, fmt.Errorf("source %v is defined more than once", src.Name)
+		case src.Location == "":
+			return nil, fmt.Errorf("source %v has no location", src.Name)
+		}
+		seen[src.Name] = true
+
+		if src.Type == "" {
+			src.Type = config.SourceTypeLocal
+		}
+		if !src.Type.IsValid() {
+			return nil, fmt.Errorf("source %v has invalid type %v", src.Name, src.Type)
+		}
+
+		src.Location = os.ExpandEnv(src.Location)
+		if src.Type == config.SourceTypeLocal {
+			src.Location = expandHome(src.Location)
+		}
+		src.Token = os.ExpandEnv(src.Token)
+		src.TokenUser = os.ExpandEnv(src.TokenUser)
+		src.KeyPath = expandHome(os.ExpandEnv(src.KeyPath))
+	}
+
+	return &conf, nil
+}
+
+// expandHome replaces a leading `~/` with the user's home directory
+func expandHome(path string) string {
+	if !strings.HasPrefix(path, "~/") {
+		return path
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[2:])
+}
+
+// Sources finds templates across several template sources, loading each one only when it is needed
+type Sources struct {
+	log     zerolog.Logger
+	configs []SourceConfig
+	loaded  map[int]*Source
+	// ref overrides the ref of the git source a template is found in
+	ref string
+}
+
+func NewSources(logger zerolog.Logger, configs []SourceConfig) *Sources {
+	return &Sources{
+		log:     logger,
+		configs: configs,
+		loaded:  map[int]*Source{},
+	}
+}
+
+// SourcesFromEnv returns the template sources to use. A template directory set by flag or environment variable is used
+// on its own, otherwise the sources in the user config, falling back to the default local template directory
+func SourcesFromEnv(logger zerolog.Logger) (*Sources, error) {
+	if viper.GetString(config.TemplateDir) != "" {
+		conf, err := envSourceConfig()
+		if err != nil {
+			return nil, err
+		}
+		return NewSources(logger, []SourceConfig{conf}), nil
+	}
+
+	userConf := &UserConfig{}
+	if path := viper.GetString(config.ConfigFile); path != "" {
+		var err error
+		userConf, err = LoadUserConfig(path)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	configs := userConf.Sources
+	if len(configs) == 0 {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return nil, fmt.Errorf("error getting user homedir: %w", err)
+		}
+
+		configs = []SourceConfig{
+			{
+				Type:     config.SourceTypeLocal,
+				Location: filepath.Join(home, ".config", "skeley", "templates"),
+			},
+		}
+	}
+
+	for i := range configs {
+		src := &configs[i]
+		if src.Type != config.SourceTypeGit {
+			continue
+		}
+
+		// Flags and environment variables fill in the auth of every git source
+		if src.Token == "" && src.KeyPath == "" {
+			src.Token = viper.GetString(config.Token)
+			src.TokenUser = viper.GetString(config.TokenUser)
+			src.KeyPath = viper.GetString(config.KeyPath)
+		}
+	}
+
+	sources := NewSources(logger, configs)
+	sources.ref = viper.GetString(config.Ref)
+	return sources, nil
+}
+
+func (s *Sources) load(i int) (*Source, error) {
+	if src, ok := s.loaded[i]; ok {
+		return src, nil
+	}
+
+	conf := s.configs[i]
+	s.log.Debug().Str("source", conf.Name).Str("location", conf.Location).Msg("loading template source")
+	src, err := LoadSource(s.log, conf)
+	if err != nil {
+		if conf.Name != "" {
+			return nil, fmt.Errorf("error loading source %v: %w", conf.Name, err)
+		}
+		return nil, err
+	}
+
+	s.loaded[i] = src
+	return src, nil
+}
+
+// Find locates a template, addressed as `source/template` or by name alone. Sources are searched in order for a
+// template without a source, and the first one containing it is used. The source is returned along with the name of
+// the template within it
+func (s *Sources) Find(name string) (*Source, string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return nil, "", fmt.Errorf("invalid template name %v", name)
+	}
+
+	candidates := []int{}
+	template := name
+	if prefix, rest, ok := strings.Cut(name, "/"); ok {
+		for i, conf := range s.configs {
+			if conf.Name != "" && conf.Name == prefix {
+				candidates = append(candidates, i)
+				template = rest
+			}
+		}
+	}
+	if len(candidates) == 0 {
+		for i := range s.configs {
+			candidates = append(candidates, i)
+		}
+	}
+	if s.ref != "" {
+		if err := s.pinRef(candidates); err != nil {
+			return nil, "", err
+		}
+	}
+
+	for _, i := range candidates {
+		src, err := s.load(i)
+		if err != nil {
+			return nil, "", err
+		}
+
+		ok, err := isTemplateDir(src.FS, template)
+		if err != nil {
+			return nil, "", err
+		}
+		if ok {
+			return src, template, nil
+		}
+	}
+
+	return nil, "", fmt.Errorf("template %v not found", name)
+}
+
+// pinRef applies the ref override to the only git source among the candidates, and fails if there are several, as it
+// would be ambiguous which one the ref belongs to
+func (s *Sources) pinRef(candidates []int) error {
+	git := []int{}
+	for _, i := range candidates {
+		if s.configs[i].Type == config.SourceTypeGit {
+			git = append(git, i)
+		}
+	}
+	if len(git) > 1 {
+		return fmt.Errorf("--%v is ambiguous with several git sources, address the template as source/template", config.Ref)
+	}
+
+	for _, i := range git {
+		if s.configs[i].Ref == s.ref && s.configs[i].BranchName == "" {
+			continue
+		}
+		s.configs[i].Ref = s.ref
+		s.configs[i].BranchName = ""
+		// Drop the source if it was already loaded at another ref
+		delete(s.loaded, i)
+	}
+	return nil
+}
+
+// ListTemplates lists the templates of every source, in search order
+func (s *Sources) ListTemplates() ([]TemplateInfo, error) {
+	templates := []TemplateInfo{}
+	for i := range s.configs {
+		src, err := s.load(i)
+		if err != nil {
+			return nil, err
+		}
+
+		sk := NewSkeley(SkeleyConfig{
+			Logger:  s.log,
+			InputFS: src.FS,
+		})
+		found, err := sk.ListTemplates()
+		if err != nil {
+			return nil, err
+		}
+
+		for _, t := range found {
+			t.Source = src.Name
+			templates = append(templates, t)
+		}
+	}
+
+	return templates, nil
+}
+
+// NewTemplateDir returns the directory to create a new template in, addressed as `source/template` or by name alone to
+// use the first local source
+func (s *Sources) NewTemplateDir(name string) (string, error) {
+	if !fs.ValidPath(name) || name == "." {
+		return "", fmt.Errorf("invalid template name %v", name)
+	}
+
+	if prefix, rest, ok := strings.Cut(name, "/"); ok {
+		for _, conf := range s.configs {
+			if conf.Name == "" || conf.Name != prefix {
+				continue
+			}
+			if conf.Type != config.SourceTypeLocal {
+				return "", fmt.Errorf("source %v is not a local source", conf.Name)
+			}
+			return filepath.Join(conf.Location, filepath.FromSlash(rest)), nil
+		}
+	}
+
+	for _, conf := range s.configs {
+		if conf.Type == config.SourceTypeLocal {
+			return filepath.Join(conf.Location, filepath.FromSlash(name)), nil
+		}
+	}
+
+	return "", fmt.Errorf("no local template source to create %v in", name)
+}
+
+// RecordedSourceConfig returns the config to load a source recorded in a manifest again. Authentication comes from the
+// user config source of the same name, falling back to flags and environment variables
+func RecordedSourceConfig(info SourceInfo) (SourceConfig, error) {
+	conf := SourceConfig{
+		Name:     info.Name,
+		Type:     info.Type,
+		Location: info.Location,
+		Ref:      info.Ref,
+	}
+
+	if path := viper.GetString(config.ConfigFile); path != "" && info.Name != "" {
+		userConf, err := LoadUserConfig(path)
+		if err != nil {
+			return SourceConfig{}, err
+		}
+		for _, src := range userConf.Sources {
+			if src.Name == info.Name && src.Location == info.Location {
+				conf.Token = src.Token
+				conf.TokenUser = src.TokenUser
+				conf.KeyPath = src.KeyPath
+			}
+		}
+	}
+
+	if conf.Token == "" && conf.KeyPath == "" {
+		conf.Token = viper.GetString(config.Token)
+		conf.TokenUser = viper.GetString(config.TokenUser)
+		conf.KeyPath = viper.GetString(config.KeyPath)
+	}
+
+	return conf, nil
+}
@@ -0,0 +1,263 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/rs/zerolog"
+	"github.com/spf13/viper"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadUserConfig(t *testing.T) {
+	writeConfig := func(t *testing.T, content string) string {
+		path := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(path, []byte(dedent.Dedent(content)), 0664))
+		return path
+	}
+
+	t.Run("sources", func(t *testing.T) {
+		home, err := os.UserHomeDir()
+		require.NoError(t, err)
+		t.Setenv("TEAM_TOKEN", "s3cret")
+
+		conf, err := LoadUserConfig(writeConfig(t, `
+			sources:
+			  - name: personal
+			    location: ~/templates
+			  - name: team
+			    type: git
+			    location: https://example.com/team/templates.git
+			    ref: v2
+			    token: ${TEAM_TOKEN}
+		`))
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]SourceConfig{
+				{Name: "personal", Type: config.SourceTypeLocal, Location: filepath.Join(home, "templates")},
+				{
+					Name:     "team",
+					Type:     config.SourceTypeGit,
+					Location: "https://example.com/team/templates.git",
+					Ref:      "v2",
+					Token:    "s3cret",
+				},
+			},
+			conf.Sources,
+		)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		conf, err := LoadUserConfig(filepath.Join(t.TempDir(), "config.yaml"))
+		require.NoError(t, err)
+		require.Empty(t, conf.Sources)
+	})
+
+	testData := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{
+			name: "no name",
+			content: `
+				sources:
+				  - location: /templates
+			`,
+			err: "has no name",
+		},
+		{
+			name: "slash in name",
+			content: `
+				sources:
+				  - name: a/b
+				    location: /templates
+			`,
+			err: `source name "a/b" cannot contain /`,
+		},
+		{
+			name: "duplicate",
+			content: `
+				sources:
+				  - name: a
+				    location: /one
+				  - name: a
+				    location: /two
+			`,
+			err: "source a is defined more than once",
+		},
+		{
+			name: "no location",
+			content: `
+				sources:
+				  - name: a
+			`,
+			err: "source a has no location",
+		},
+		{
+			name: "bad type",
+			content: `
+				sources:
+				  - name: a
+				    type: svn
+				    location: /templates
+			`,
+			err: "svn is not a valid SourceType",
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := LoadUserConfig(writeConfig(t, tc.content))
+			require.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestSources(t *testing.T) {
+	newLocal := func(t *testing.T, templates ...string) string {
+		dir := t.TempDir()
+		for _, name := range templates {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.FromSlash(name), "files"), 0775))
+		}
+		return dir
+	}
+
+	personal := newLocal(t, "service", "go/cli")
+	repo := newTemplateRepo(t)
+	head := repo.commit(map[string]string{
+		"service/files/main.go": "package main\n",
+		"library/files/lib.go":  "package lib\n",
+	})
+	repo.push()
+
+	sources := NewSources(zerolog.Nop(), []SourceConfig{
+		{Name: "personal", Type: config.SourceTypeLocal, Location: personal},
+		{Name: "team", Type: config.SourceTypeGit, Location: repo.URL()},
+	})
+
+	testData := []struct {
+		name     string
+		template string
+		source   string
+		found    string
+		err      string
+	}{
+		{name: "first in search order", template: "service", source: "personal", found: "service"},
+		{name: "later source", template: "library", source: "team", found: "library"},
+		{name: "addressed by source", template: "team/service", source: "team", found: "service"},
+		{name: "nested", template: "go/cli", source: "personal", found: "go/cli"},
+		{name: "nested by source", template: "personal/go/cli", source: "personal", found: "go/cli"},
+		{name: "not in source", template: "team/go/cli", err: "template team/go/cli not found"},
+		{name: "missing", template: "nope", err: "template nope not found"},
+		{name: "invalid", template: "../service", err: "invalid template name ../service"},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			source, template, err := sources.Find(tc.template)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.source, source.Name)
+			require.Equal(t, tc.found, template)
+		})
+	}
+
+	t.Run("list", func(t *testing.T) {
+		templates, err := sources.ListTemplates()
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]TemplateInfo{
+				{Template: "service", Source: "personal"},
+				{Template: "library", Source: "team"},
+				{Template: "service", Source: "team"},
+			},
+			templates,
+		)
+	})
+
+	t.Run("new template dir", func(t *testing.T) {
+		dir, err := sources.NewTemplateDir("widget")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(personal, "widget"), dir)
+
+		dir, err = sources.NewTemplateDir("personal/go/widget")
+		require.NoError(t, err)
+		require.Equal(t, filepath.Join(personal, "go", "widget"), dir)
+
+		_, err = sources.NewTemplateDir("team/widget")
+		require.EqualError(t, err, "source team is not a local source")
+
+		_, err = NewSources(zerolog.Nop(), []SourceConfig{{Type: config.SourceTypeGit}}).NewTemplateDir("widget")
+		require.EqualError(t, err, "no local template source to create widget in")
+	})
+
+	t.Run("from env", func(t *testing.T) {
+		configPath := filepath.Join(t.TempDir(), "config.yaml")
+		require.NoError(t, os.WriteFile(configPath, []byte(dedent.Dedent(`
+			sources:
+			  - name: personal
+			    location: `+personal+`
+			  - name: team
+			    type: git
+			    location: `+repo.URL()+`
+			    token: team-token
+		`)), 0664))
+
+		t.Run("user config", func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			viper.Set(config.ConfigFile, configPath)
+			viper.Set(config.Ref, head)
+			viper.Set(config.Token, "env-token")
+
+			sources, err := SourcesFromEnv(zerolog.Nop())
+			require.NoError(t, err)
+			require.Len(t, sources.configs, 2)
+			require.Equal(t, "team-token", sources.configs[1].Token)
+
+			// The ref only applies to the git source the template is found in
+			source, _, err := sources.Find("team/service")
+			require.NoError(t, err)
+			require.Equal(t, head, source.Commit)
+			require.Equal(t, "", sources.configs[0].Ref)
+			require.Equal(t, head, sources.configs[1].Ref)
+		})
+
+		t.Run("ambiguous ref", func(t *testing.T) {
+			sources := NewSources(zerolog.Nop(), []SourceConfig{
+				{Name: "team", Type: config.SourceTypeGit, Location: repo.URL()},
+				{Name: "other", Type: config.SourceTypeGit, Location: repo.URL()},
+			})
+			sources.ref = head
+
+			_, _, err := sources.Find("service")
+			require.EqualError(t, err, "--ref is ambiguous with several git sources, address the template as source/template")
+
+			source, _, err := sources.Find("other/service")
+			require.NoError(t, err)
+			require.Equal(t, "other", source.Name)
+			require.Equal(t, "", sources.configs[0].Ref)
+		})
+
+		t.Run("template dir wins", func(t *testing.T) {
+			t.Cleanup(viper.Reset)
+			viper.Set(config.ConfigFile, configPath)
+			viper.Set(config.TemplateDir, personal)
+			viper.Set(config.InputType, config.SourceTypeLocal)
+
+			sources, err := SourcesFromEnv(zerolog.Nop())
+			require.NoError(t, err)
+			require.Equal(t, []SourceConfig{{Type: config.SourceTypeLocal, Location: personal}}, sources.configs)
+
+			_, template, err := sources.Find("go/cli")
+			require.NoError(t, err)
+			require.Equal(t, "go/cli", template)
+		})
+	})
+}
@@ -0,0 +1,68 @@
+package internal
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"strings"
+	"text/template"
+)
+
+// templateSet holds the parsed template files. Files are rendered with text/template unless they are HTML files or
+// the template has opted into HTML escaping, in which case html/template is used so values are escaped
+type templateSet struct {
+	text    *template.Template
+	html    *htmltemplate.Template
+	allHTML bool
+	isHTML  map[string]bool
+	// partials holds the names of templates that are only used from other templates
+	partials map[string]bool
+}
+
+func newTemplateSet(funcMap template.FuncMap, allHTML bool) *templateSet {
+	return &templateSet{
+		text:     template.New("").Funcs(funcMap),
+		html:     htmltemplate.New("").Funcs(funcMap),
+		allHTML:  allHTML,
+		isHTML:   map[string]bool{},
+		partials: map[string]bool{},
+	}
+}
+
+func (t *templateSet) useHTML(name string) bool {
+	return t.allHTML || strings.HasSuffix(name, ".html")
+}
+
+// parsePartial adds a template to both the text and HTML sets, so it can be used from any file
+func (t *templateSet) parsePartial(name string, content string) error {
+	t.partials[name] = true
+
+	if _, err := t.text.New(name).Parse(content); err != nil {
+		return err
+	}
+	_, err := t.html.New(name).Parse(content)
+	return err
+}
+
+func (t *templateSet) parse(name string, content string) error {
+	if t.partials[name] {
+		return fmt.Errorf("file %v has the same name as a partial", name)
+	}
+
+	if t.useHTML(name) {
+		t.isHTML[name] = true
+		_, err := t.html.New(name).Parse(content)
+		return err
+	}
+
+	_, err := t.text.New(name).Parse(content)
+	return err
+}
+
+func (t *templateSet) execute(w io.Writer, name string, data any) error {
+	if t.isHTML[name] {
+		return t.html.ExecuteTemplate(w, name, data)
+	}
+
+	return t.text.ExecuteTemplate(w, name, data)
+}
@@ -0,0 +1,67 @@
+package internal
+
+import (
+	"strings"
+	"testing"
+	"text/template"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateSet(t *testing.T) {
+	const special = `<a href="x">Tom & Jerry's</a>`
+
+	testData := []struct {
+		name       string
+		file       string
+		escapeHTML bool
+		expected   string
+	}{
+		{
+			name:     "go file is verbatim",
+			file:     "main.go",
+			expected: `const v = "<a href="x">Tom & Jerry's</a>"`,
+		},
+		{
+			name:     "yaml file is verbatim",
+			file:     "config.yaml",
+			expected: `const v = "<a href="x">Tom & Jerry's</a>"`,
+		},
+		{
+			name:     "dockerfile is verbatim",
+			file:     "Dockerfile",
+			expected: `const v = "<a href="x">Tom & Jerry's</a>"`,
+		},
+		{
+			name:     "shell script is verbatim",
+			file:     "scripts/run.sh",
+			expected: `const v = "<a href="x">Tom & Jerry's</a>"`,
+		},
+		{
+			name:     "html file is escaped",
+			file:     "index.html",
+			expected: `const v = "&lt;a href=&#34;x&#34;&gt;Tom &amp; Jerry&#39;s&lt;/a&gt;"`,
+		},
+		{
+			name:     "tmpl html file is escaped",
+			file:     "page.tmpl.html",
+			expected: `const v = "&lt;a href=&#34;x&#34;&gt;Tom &amp; Jerry&#39;s&lt;/a&gt;"`,
+		},
+		{
+			name:       "escape html option",
+			file:       "main.go",
+			escapeHTML: true,
+			expected:   `const v = "&lt;a href=&#34;x&#34;&gt;Tom &amp; Jerry&#39;s&lt;/a&gt;"`,
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			set := newTemplateSet(template.FuncMap{}, tc.escapeHTML)
+			require.NoError(t, set.parse(tc.file, `const v = "{{ .Value }}"`))
+
+			var out strings.Builder
+			require.NoError(t, set.execute(&out, tc.file, map[string]string{"Value": special}))
+			require.Equal(t, tc.expected, out.String())
+		})
+	}
+}
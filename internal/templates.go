@@ -0,0 +1,223 @@
+package internal
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"path"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nicjohnson145/skeley/config"
+)
+
+// readmeNames are the files checked, in order, for a template's README
+var readmeNames = []string{"README.md", "README"}
+
+// TemplateInfo describes a template in a source
+type TemplateInfo struct {
+	// Template is the directory of the template, the name it is rendered with
+	Template    string   `json:"template"`
+	Name        string   `json:"name,omitempty"`
+	Description string   `json:"description,omitempty"`
+	Tags        []string `json:"tags,omitempty"`
+	Maintainers []string `json:"maintainers,omitempty"`
+	// Source is the name of the source the template is in, if it came from the user config
+	Source string `json:"source,omitempty"`
+}
+
+func (c templateConfig) info(template string) TemplateInfo {
+	return TemplateInfo{
+		Template:    template,
+		Name:        c.Name,
+		Description: c.Description,
+		Tags:        c.Tags,
+		Maintainers: c.Maintainers,
+	}
+}
+
+// isTemplateDir reports if dir contains a `files/` directory or a `config.yaml`
+func isTemplateDir(fsys fs.FS, dir string) (bool, error) {
+	info, err := fs.Stat(fsys, path.Join(dir, "files"))
+	if err == nil && info.IsDir() {
+		return true, nil
+	}
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("error reading %v: %w", dir, err)
+	}
+
+	_, err = fs.Stat(fsys, path.Join(dir, "config.yaml"))
+	if err == nil {
+		return true, nil
+	}
+	if !errors.Is(err, fs.ErrNotExist) {
+		return false, fmt.Errorf("error reading %v: %w", dir, err)
+	}
+
+	return false, nil
+}
+
+// FilterTemplates returns the templates that have every one of the tags
+func FilterTemplates(templates []TemplateInfo, tags []string) []TemplateInfo {
+	filtered := []TemplateInfo{}
+	for _, t := range templates {
+		matches := true
+		for _, tag := range tags {
+			if !contains(t.Tags, tag) {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			filtered = append(filtered, t)
+		}
+	}
+	return filtered
+}
+
+// WriteTemplates prints the templates in the given format
+func WriteTemplates(w io.Writer, templates []TemplateInfo, format config.OutputFormat) error {
+	switch format {
+	case config.OutputFormatJson:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(templates)
+	case config.OutputFormatText:
+		tw := newTable(w)
+		fmt.Fprintln(tw, "TEMPLATE\tNAME\tDESCRIPTION\tSOURCE")
+		for _, t := range templates {
+			fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", t.Template, t.Name, t.Description, t.Source)
+		}
+		return tw.Flush()
+	default:
+		return fmt.Errorf("unhandled output format %v", format)
+	}
+}
+
+// table aligns tab separated cells like a tabwriter, without padding the end of rows with empty trailing cells
+type table struct {
+	*tabwriter.Writer
+	buf *bytes.Buffer
+	out io.Writer
+}
+
+func newTable(w io.Writer) *table {
+	buf := &bytes.Buffer{}
+	return &table{
+		Writer: tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0),
+		buf:    buf,
+		out:    w,
+	}
+}
+
+func (t *table) Flush() error {
+	if err := t.Writer.Flush(); err != nil {
+		return err
+	}
+
+	for _, line := range splitLines(t.buf.String()) {
+		if _, err := io.WriteString(t.out, strings.TrimRight(line, " \n")+"\n"); err != nil {
+			return err
+		}
+	}
+	t.buf.Reset()
+
+	return nil
+}
+
+// ShowTemplate prints the metadata, variables, hooks, parents and README of the template
+func (s *Skeley) ShowTemplate(w io.Writer) error {
+	ok, err := isTemplateDir(s.inputFS, ".")
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return fmt.Errorf("%v is not a template", s.conf.Template)
+	}
+
+	tmpl, err := s.loadTemplate()
+	if err != nil {
+		return err
+	}
+
+	// Metadata isn't inherited, so describe the template from its own config
+	conf, err := s.getTemplateConfig()
+	if err != nil {
+		return err
+	}
+	info := conf.info(s.conf.Template)
+
+	tw := newTable(w)
+	for _, field := range [][2]string{
+		{"template", info.Template},
+		{"name", info.Name},
+		{"description", info.Description},
+		{"tags", strings.Join(info.Tags, ", ")},
+		{"maintainers", strings.Join(info.Maintainers, ", ")},
+		{"extends", strings.Join(tmpl.chain, " -> ")},
+	} {
+		if field[1] != "" {
+			fmt.Fprintf(tw, "%v\t%v\n", field[0], field[1])
+		}
+	}
+
+	if len(tmpl.conf.Variables) > 0 {
+		fmt.Fprintln(tw, "\nvariables")
+		for _, v := range tmpl.conf.Variables {
+			def := ""
+			switch {
+			case v.Required:
+				def = "required"
+			case v.Secret && v.Default != nil:
+				def = "(secret)"
+			case v.Default != nil:
+				def = fmt.Sprint(v.Default)
+			}
+
+			desc := v.Description
+			if len(v.Choices) > 0 {
+				desc = strings.TrimSpace(fmt.Sprintf("%v (one of %v)", desc, strings.Join(v.Choices, ", ")))
+			}
+
+			fmt.Fprintf(tw, "  %v\t%v\t%v\t%v\n", v.Name, v.varType(), def, desc)
+		}
+	}
+
+	for _, hook := range []struct {
+		stage string
+		cmds  []string
+	}{
+		{stage: "pre-cmds", cmds: tmpl.conf.PreCmds},
+		{stage: "post-cmds", cmds: tmpl.conf.PostCmds},
+	} {
+		if len(hook.cmds) == 0 {
+			continue
+		}
+		fmt.Fprintf(tw, "\n%v\n", hook.stage)
+		for _, c := range hook.cmds {
+			fmt.Fprintf(tw, "  %v\n", c)
+		}
+	}
+
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+
+	for _, name := range readmeNames {
+		content, err := fs.ReadFile(s.inputFS, name)
+		if errors.Is(err, fs.ErrNotExist) {
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("error reading %v: %w", name, err)
+		}
+
+		_, err = fmt.Fprintf(w, "\n%s", content)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package internal
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTemplateMetadata(t *testing.T) {
+	newSource := func(t *testing.T) *memfs.FS {
+		src := memfs.New()
+
+		require.NoError(t, src.MkdirAll("base/files", 0775))
+		require.NoError(t, src.WriteFile("base/config.yaml", []byte(dedent.Dedent(`
+			name: Base
+			description: Shared project layout
+			tags: [go]
+			pre-cmds:
+			  - git init
+			variables:
+			  - name: Owner
+			    required: true
+			    description: Team that owns the service
+		`)), 0664))
+
+		require.NoError(t, src.MkdirAll("service/files", 0775))
+		require.NoError(t, src.WriteFile("service/config.yaml", []byte(dedent.Dedent(`
+			extends: base
+			name: Service
+			description: An HTTP service
+			tags: [go, http]
+			maintainers:
+			  - alice@example.com
+			post-cmds:
+			  - go mod tidy
+			variables:
+			  - name: Port
+			    type: int
+			    default: 8080
+			  - name: Log
+			    choices: [json, text]
+			  - name: Token
+			    secret: true
+			    default: hunter2
+		`)), 0664))
+		require.NoError(t, src.WriteFile("service/README.md", []byte("# Service\n\nRenders a service.\n"), 0664))
+
+		require.NoError(t, src.MkdirAll("docs", 0775))
+		require.NoError(t, src.WriteFile("docs/index.md", []byte("# Templates\n"), 0664))
+
+		return src
+	}
+
+	t.Run("list", func(t *testing.T) {
+		sk := NewSkeley(SkeleyConfig{
+			InputFS: newSource(t),
+		})
+
+		templates, err := sk.ListTemplates()
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			[]TemplateInfo{
+				{Template: "base", Name: "Base", Description: "Shared project layout", Tags: []string{"go"}},
+				{
+					Template:    "service",
+					Name:        "Service",
+					Description: "An HTTP service",
+					Tags:        []string{"go", "http"},
+					Maintainers: []string{"alice@example.com"},
+				},
+			},
+			templates,
+		)
+
+		testData := []struct {
+			name     string
+			tags     []string
+			expected []string
+		}{
+			{name: "no tags", expected: []string{"base", "service"}},
+			{name: "shared tag", tags: []string{"go"}, expected: []string{"base", "service"}},
+			{name: "every tag", tags: []string{"go", "http"}, expected: []string{"service"}},
+			{name: "no match", tags: []string{"rust"}, expected: []string{}},
+		}
+		for _, tc := range testData {
+			t.Run(tc.name, func(t *testing.T) {
+				names := []string{}
+				for _, tmpl := range FilterTemplates(templates, tc.tags) {
+					names = append(names, tmpl.Template)
+				}
+				require.Equal(t, tc.expected, names)
+			})
+		}
+
+		t.Run("text", func(t *testing.T) {
+			withSource := append([]TemplateInfo{}, templates...)
+			withSource[1].Source = "team"
+
+			buf := &bytes.Buffer{}
+			require.NoError(t, WriteTemplates(buf, withSource, config.OutputFormatText))
+			require.Equal(
+				t,
+				dedent.Dedent(`
+					TEMPLATE  NAME     DESCRIPTION            SOURCE
+					base      Base     Shared project layout
+					service   Service  An HTTP service        team
+				`)[1:],
+				buf.String(),
+			)
+		})
+
+		t.Run("json", func(t *testing.T) {
+			buf := &bytes.Buffer{}
+			require.NoError(t, WriteTemplates(buf, templates[:1], config.OutputFormatJson))
+			require.JSONEq(
+				t,
+				`[{"template": "base", "name": "Base", "description": "Shared project layout", "tags": ["go"]}]`,
+				buf.String(),
+			)
+		})
+	})
+
+	t.Run("show", func(t *testing.T) {
+		src := newSource(t)
+		sk := newTemplateSkeley(t, src, "service")
+
+		buf := &bytes.Buffer{}
+		require.NoError(t, sk.ShowTemplate(buf))
+		require.Equal(
+			t,
+			dedent.Dedent(`
+				template     service
+				name         Service
+				description  An HTTP service
+				tags         go, http
+				maintainers  alice@example.com
+				extends      base
+
+				variables
+				  Owner  string  required  Team that owns the service
+				  Port   int     8080
+				  Log    string            (one of json, text)
+				  Token  string  (secret)
+
+				pre-cmds
+				  git init
+
+				post-cmds
+				  go mod tidy
+
+				# Service
+
+				Renders a service.
+			`)[1:],
+			buf.String(),
+		)
+	})
+
+	t.Run("show not a template", func(t *testing.T) {
+		sk := newTemplateSkeley(t, newSource(t), "docs")
+		require.EqualError(t, sk.ShowTemplate(&bytes.Buffer{}), "docs is not a template")
+	})
+}
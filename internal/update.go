@@ -0,0 +1,238 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"text/tabwriter"
+)
+
+//go:generate go-enum -f $GOFILE -marshal -names
+
+/*
+ENUM(
+create
+update
+unchanged
+conflict
+delete
+keep
+)
+*/
+type UpdateAction string
+
+// UpdatedFile is a single file changed, or left alone, by updating a project
+type UpdatedFile struct {
+	// Path is the slash separated path of the file, relative to the project directory
+	Path   string
+	Action UpdateAction
+}
+
+// Update brings the project in the output directory up to date with the template, which must be the one recorded in
+// its manifest. Both the version the project was generated from, found in previous, and the current version are
+// rendered with the recorded answers. The changes between them are then merged into the project's files, leaving
+// conflict markers where they overlap with local edits, or writing the template's version to `<file>.new` for files
+// that can't be merged. Files the template no longer produces are deleted unless they
+// were edited. Hooks are not run
+func (s *Skeley) Update(previous fs.FS) ([]UpdatedFile, error) {
+	manifest, err := ReadManifest(s.outputPath)
+	if err != nil {
+		return nil, err
+	}
+	if manifest.Template != s.conf.Template {
+		return nil, fmt.Errorf("project was generated from template %v, not %v", manifest.Template, s.conf.Template)
+	}
+
+	tmpl, values, err := s.prepare()
+	if err != nil {
+		return nil, err
+	}
+	plan, err := s.buildPlan(tmpl, values)
+	if err != nil {
+		return nil, err
+	}
+
+	oldPlan, err := s.previousPlan(previous, manifest, values)
+	if err != nil {
+		return nil, fmt.Errorf("error rendering previous template version: %w", err)
+	}
+	oldFiles := map[string]PlannedFile{}
+	for _, f := range oldPlan.Files {
+		oldFiles[f.Path] = f
+	}
+
+	updated := []UpdatedFile{}
+	applied := []PlannedFile{}
+	for _, f := range plan.Files {
+		action, ok, err := s.updateFile(oldFiles[f.Path], f)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, UpdatedFile{Path: f.Path, Action: action})
+		if ok {
+			applied = append(applied, f)
+		}
+		delete(oldFiles, f.Path)
+	}
+	for _, f := range oldPlan.Files {
+		if _, ok := oldFiles[f.Path]; !ok {
+			continue
+		}
+		action, err := s.removeFile(f)
+		if err != nil {
+			return nil, err
+		}
+		updated = append(updated, UpdatedFile{Path: f.Path, Action: action})
+	}
+
+	if err := s.writeManifest(tmpl, values, applied); err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// previousPlan renders the template from previous with the recorded answers, so answers changed since are merged into
+// the project like any other change. Current values are only used for variables without a recorded answer, such as
+// secrets
+func (s *Skeley) previousPlan(previous fs.FS, manifest *Manifest, values map[string]any) (*Plan, error) {
+	inputFS, err := fs.Sub(previous, manifest.Template)
+	if err != nil {
+		return nil, err
+	}
+
+	old := NewSkeley(SkeleyConfig{
+		Logger:     s.log,
+		InputFS:    inputFS,
+		SourceFS:   previous,
+		Template:   manifest.Template,
+		OutputPath: s.outputPath,
+	})
+	tmpl, err := old.loadTemplate()
+	if err != nil {
+		return nil, err
+	}
+
+	// Only pass values for variables the previous version declares, so variables added since aren't warned about
+	old.conf.Values = map[string]any{}
+	for _, d := range tmpl.conf.Variables {
+		if val, ok := manifest.Answers[d.Name]; ok {
+			old.conf.Values[d.Name] = val
+		} else if val, ok := values[d.Name]; ok {
+			old.conf.Values[d.Name] = val
+		}
+	}
+	oldValues, err := old.resolveValues(tmpl.conf.Variables)
+	if err != nil {
+		return nil, err
+	}
+
+	return old.buildPlan(tmpl, oldValues)
+}
+
+// updateFile merges the template's changes to a file into the project. old is the file from the previous version, and
+// has no path if the file is new to the template. The returned bool is false if the project's version was kept without
+// the template's changes
+func (s *Skeley) updateFile(old PlannedFile, f PlannedFile) (UpdateAction, bool, error) {
+	current, err := os.ReadFile(s.outputFile(f.Path))
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			return "", false, err
+		}
+		if old.Path != "" {
+			s.log.Info().Str("path", f.Path).Msg("file was deleted from the project, not restoring it")
+			return UpdateActionKeep, false, nil
+		}
+		s.log.Debug().Str("path", f.Path).Msg("creating file")
+		return UpdateActionCreate, true, s.writeFile(f)
+	}
+
+	updated, err := f.read()
+	if err != nil {
+		return "", false, err
+	}
+	var base []byte
+	if old.Path != "" {
+		if base, err = old.read(); err != nil {
+			return "", false, err
+		}
+	}
+
+	switch {
+	case bytes.Equal(current, updated), old.Path != "" && bytes.Equal(base, updated):
+		return UpdateActionUnchanged, true, nil
+	case old.Path != "" && bytes.Equal(current, base):
+		s.log.Debug().Str("path", f.Path).Msg("file is unedited, replacing it")
+		return UpdateActionUpdate, true, s.writeFile(f)
+	case f.copy || isBinary(current) || isBinary(updated) || isBinary(base):
+		// These can't be merged, so write the template's version next to the project's for the user to resolve
+		s.log.Warn().Str("path", f.Path).Msg("file was changed by both the project and the template, writing the template's version to <file>.new")
+		theirs := f
+		theirs.Path = f.Path + ".new"
+		if err := s.writeFile(theirs); err != nil {
+			return "", false, err
+		}
+		return UpdateActionConflict, false, nil
+	}
+
+	merged, conflict := merge3(string(base), string(current), string(updated))
+	if err := s.writeFile(PlannedFile{Path: f.Path, content: []byte(merged)}); err != nil {
+		return "", false, err
+	}
+	if conflict {
+		s.log.Warn().Str("path", f.Path).Msg("file has conflicts")
+		return UpdateActionConflict, true, nil
+	}
+	return UpdateActionUpdate, true, nil
+}
+
+// removeFile deletes a file the template no longer produces, unless it was edited in the project
+func (s *Skeley) removeFile(old PlannedFile) (UpdateAction, error) {
+	current, err := os.ReadFile(s.outputFile(old.Path))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return UpdateActionUnchanged, nil
+		}
+		return "", err
+	}
+
+	base, err := old.read()
+	if err != nil {
+		return "", err
+	}
+	if !bytes.Equal(current, base) {
+		s.log.Info().Str("path", old.Path).Msg("file was removed from the template but edited in the project, keeping it")
+		return UpdateActionKeep, nil
+	}
+
+	s.log.Debug().Str("path", old.Path).Msg("removing file")
+	if err := os.Remove(s.outputFile(old.Path)); err != nil {
+		return "", fmt.Errorf("error removing %v: %w", old.Path, err)
+	}
+	return UpdateActionDelete, nil
+}
+
+func (f PlannedFile) read() ([]byte, error) {
+	r, err := f.open()
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	return io.ReadAll(r)
+}
+
+// WriteUpdate prints what updating did to each file
+func WriteUpdate(w io.Writer, files []UpdatedFile) error {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	for _, f := range files {
+		if f.Action == UpdateActionUnchanged {
+			continue
+		}
+		fmt.Fprintf(tw, "%v\t%v\n", f.Action, f.Path)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,89 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version: 0.5.8
+// Revision: 3d844c8ecc59661ed7aa17bfd65727bc06a60ad8
+// Build Date: 2023-09-18T14:55:21Z
+// Built By: goreleaser
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// UpdateActionCreate is a UpdateAction of type create.
+	UpdateActionCreate UpdateAction = "create"
+	// UpdateActionUpdate is a UpdateAction of type update.
+	UpdateActionUpdate UpdateAction = "update"
+	// UpdateActionUnchanged is a UpdateAction of type unchanged.
+	UpdateActionUnchanged UpdateAction = "unchanged"
+	// UpdateActionConflict is a UpdateAction of type conflict.
+	UpdateActionConflict UpdateAction = "conflict"
+	// UpdateActionDelete is a UpdateAction of type delete.
+	UpdateActionDelete UpdateAction = "delete"
+	// UpdateActionKeep is a UpdateAction of type keep.
+	UpdateActionKeep UpdateAction = "keep"
+)
+
+var ErrInvalidUpdateAction = fmt.Errorf("not a valid UpdateAction, try [%s]", strings.Join(_UpdateActionNames, ", "))
+
+var _UpdateActionNames = []string{
+	string(UpdateActionCreate),
+	string(UpdateActionUpdate),
+	string(UpdateActionUnchanged),
+	string(UpdateActionConflict),
+	string(UpdateActionDelete),
+	string(UpdateActionKeep),
+}
+
+// UpdateActionNames returns a list of possible string values of UpdateAction.
+func UpdateActionNames() []string {
+	tmp := make([]string, len(_UpdateActionNames))
+	copy(tmp, _UpdateActionNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x UpdateAction) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x UpdateAction) IsValid() bool {
+	_, err := ParseUpdateAction(string(x))
+	return err == nil
+}
+
+var _UpdateActionValue = map[string]UpdateAction{
+	"create":    UpdateActionCreate,
+	"update":    UpdateActionUpdate,
+	"unchanged": UpdateActionUnchanged,
+	"conflict":  UpdateActionConflict,
+	"delete":    UpdateActionDelete,
+	"keep":      UpdateActionKeep,
+}
+
+// ParseUpdateAction attempts to convert a string to a UpdateAction.
+func ParseUpdateAction(name string) (UpdateAction, error) {
+	if x, ok := _UpdateActionValue[name]; ok {
+		return x, nil
+	}
+	return UpdateAction(""), fmt.Errorf("%s is %w", name, ErrInvalidUpdateAction)
+}
+
+// MarshalText implements the text marshaller method.
+func (x UpdateAction) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *UpdateAction) UnmarshalText(text []byte) error {
+	tmp, err := ParseUpdateAction(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
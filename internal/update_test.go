@@ -0,0 +1,150 @@
+package internal
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/nicjohnson145/skeley/config"
+	"github.com/rs/zerolog"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUpdate(t *testing.T) {
+	repo := newTemplateRepo(t)
+	v1 := repo.commit(map[string]string{
+		"service/config.yaml":       "not-module: true\nvariables:\n  - name: Name\n",
+		"service/files/README.md":   "# {{ .Name }}\n\nAbout.\n\nUsage.\n",
+		"service/files/Makefile":    "build:\n\tgo build\n",
+		"service/files/ci.yaml":     "lint: false\n",
+		"service/files/old.txt":     "old\n",
+		"service/files/edited.txt":  "edited\n",
+		"service/files/deleted.txt": "deleted\n",
+		"service/files/logo.png":    "\x89PNG\x00v1",
+	})
+	v2 := repo.commit(map[string]string{
+		"service/config.yaml":       "not-module: true\nvariables:\n  - name: Name\n  - name: Owner\n    default: platform\n",
+		"service/files/README.md":   "# {{ .Name }}\n\nAbout.\n\nUsage, owned by {{ .Owner }}.\n",
+		"service/files/Makefile":    "build:\n\tgo build ./...\n",
+		"service/files/ci.yaml":     "lint: true\n",
+		"service/files/new.txt":     "new\n",
+		"service/files/deleted.txt": "deleted v2\n",
+		"service/files/old.txt":     "",
+		"service/files/edited.txt":  "",
+		"service/files/logo.png":    "\x89PNG\x00v2",
+	})
+	repo.push()
+
+	load := func(t *testing.T, ref string) *Source {
+		t.Helper()
+		source, err := LoadSource(zerolog.Nop(), SourceConfig{Type: config.SourceTypeGit, Location: repo.URL(), Ref: ref})
+		require.NoError(t, err)
+		return source
+	}
+	skeley := func(t *testing.T, source *Source, dir string) *Skeley {
+		t.Helper()
+		sk := newTemplateSkeley(t, source.FS, "service")
+		sk.conf.SourceInfo = source.SourceInfo
+		sk.conf.Values = map[string]any{"Name": "billing"}
+		sk.outputPath = dir
+		return sk
+	}
+	readFile := func(t *testing.T, path string) string {
+		t.Helper()
+		content, err := os.ReadFile(path)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	dir := t.TempDir()
+	previous := load(t, v1)
+	require.NoError(t, skeley(t, previous, dir).Execute())
+
+	// Local edits, some overlapping the template's changes
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# billing\n\nAbout billing.\n\nUsage.\n"), 0664))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "Makefile"), []byte("build:\n\tgo build -v\n"), 0664))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "edited.txt"), []byte("edited locally\n"), 0664))
+	require.NoError(t, os.Remove(filepath.Join(dir, "deleted.txt")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "logo.png"), []byte("\x89PNG\x00local"), 0664))
+
+	updated, err := skeley(t, load(t, ""), dir).Update(previous.FS)
+	require.NoError(t, err)
+
+	actions := map[string]UpdateAction{}
+	for _, f := range updated {
+		actions[f.Path] = f.Action
+	}
+	require.Equal(
+		t,
+		map[string]UpdateAction{
+			"README.md":   UpdateActionUpdate,
+			"Makefile":    UpdateActionConflict,
+			"ci.yaml":     UpdateActionUpdate,
+			"new.txt":     UpdateActionCreate,
+			"deleted.txt": UpdateActionKeep,
+			"old.txt":     UpdateActionDelete,
+			"edited.txt":  UpdateActionKeep,
+			"logo.png":    UpdateActionConflict,
+		},
+		actions,
+	)
+
+	require.Equal(t, "# billing\n\nAbout billing.\n\nUsage, owned by platform.\n", readFile(t, filepath.Join(dir, "README.md")))
+	require.Equal(
+		t,
+		"build:\n<<<<<<< current\n\tgo build -v\n=======\n\tgo build ./...\n>>>>>>> template\n",
+		readFile(t, filepath.Join(dir, "Makefile")),
+	)
+	require.Equal(t, "lint: true\n", readFile(t, filepath.Join(dir, "ci.yaml")))
+	require.Equal(t, "new\n", readFile(t, filepath.Join(dir, "new.txt")))
+	require.Equal(t, "edited locally\n", readFile(t, filepath.Join(dir, "edited.txt")))
+	require.NoFileExists(t, filepath.Join(dir, "old.txt"))
+	require.NoFileExists(t, filepath.Join(dir, "deleted.txt"))
+	// Binary files can't be merged, so the template's version is written next to the project's
+	require.Equal(t, "\x89PNG\x00local", readFile(t, filepath.Join(dir, "logo.png")))
+	require.Equal(t, "\x89PNG\x00v2", readFile(t, filepath.Join(dir, "logo.png.new")))
+
+	m, err := ReadManifest(dir)
+	require.NoError(t, err)
+	require.Equal(t, v2, m.Source.Commit)
+	require.Equal(t, map[string]any{"Name": "billing", "Owner": "platform"}, m.Answers)
+	// The template's versions of files deleted or kept in the project aren't recorded, as they weren't written
+	require.NotContains(t, m.Files, "deleted.txt")
+	require.NotContains(t, m.Files, "logo.png")
+	require.Contains(t, m.Files, "README.md")
+
+	buf := &bytes.Buffer{}
+	require.NoError(t, WriteUpdate(buf, updated))
+	require.Equal(
+		t,
+		"conflict  Makefile\nupdate    README.md\nupdate    ci.yaml\nkeep      deleted.txt\nconflict  logo.png\ncreate    new.txt\nkeep      edited.txt\ndelete    old.txt\n",
+		buf.String(),
+	)
+
+	t.Run("up to date", func(t *testing.T) {
+		updated, err := skeley(t, load(t, ""), dir).Update(load(t, v2).FS)
+		require.NoError(t, err)
+		for _, f := range updated {
+			require.Contains(t, []UpdateAction{UpdateActionUnchanged, UpdateActionKeep}, f.Action, f.Path)
+		}
+	})
+
+	t.Run("changed answer", func(t *testing.T) {
+		sk := skeley(t, load(t, ""), dir)
+		sk.conf.Values = map[string]any{"Name": "payments", "Owner": "platform"}
+		updated, err := sk.Update(load(t, v2).FS)
+		require.NoError(t, err)
+		require.Contains(t, updated, UpdatedFile{Path: "README.md", Action: UpdateActionUpdate})
+
+		require.Equal(t, "# payments\n\nAbout billing.\n\nUsage, owned by platform.\n", readFile(t, filepath.Join(dir, "README.md")))
+		m, err := ReadManifest(dir)
+		require.NoError(t, err)
+		require.Equal(t, "payments", m.Answers["Name"])
+	})
+
+	t.Run("not generated", func(t *testing.T) {
+		_, err := skeley(t, load(t, ""), t.TempDir()).Update(previous.FS)
+		require.ErrorContains(t, err, "it wasn't generated by skeley")
+	})
+}
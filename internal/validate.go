@@ -0,0 +1,483 @@
+package internal
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"text/template/parse"
+
+	"gopkg.in/yaml.v3"
+)
+
+// legacyBuiltins are the builtins also exposed at the top level of the render context
+var legacyBuiltins = []string{"Module", "BinaryName", "GoVersion"}
+
+var (
+	yamlErrLine     = regexp.MustCompile(`^(?:yaml: )?line (\d+): (.*)$`)
+	yamlUnknownKey  = regexp.MustCompile(`^field (\S+) not found in type \S+$`)
+	templateErrLine = regexp.MustCompile(`^template: .*?:(\d+):(?:\d+:)? (.*)$`)
+)
+
+// Diagnostic is a problem found while validating a template
+type Diagnostic struct {
+	// File is the slash separated path of the file within the template
+	File    string `json:"file"`
+	Line    int    `json:"line,omitempty"`
+	Message string `json:"message"`
+}
+
+func (d Diagnostic) String() string {
+	if d.Line == 0 {
+		return fmt.Sprintf("%v: %v", d.File, d.Message)
+	}
+	return fmt.Sprintf("%v:%v: %v", d.File, d.Line, d.Message)
+}
+
+type validator struct {
+	diags []Diagnostic
+	// doc is the parsed config.yaml, for finding the line of config problems
+	doc *yaml.Node
+}
+
+func (v *validator) add(file string, line int, format string, args ...any) {
+	v.diags = append(v.diags, Diagnostic{File: file, Line: line, Message: fmt.Sprintf(format, args...)})
+}
+
+// configLine is the line in config.yaml of the key or item at path, made of mapping keys and sequence indexes
+func (v *validator) configLine(path ...any) int {
+	if v.doc == nil || len(v.doc.Content) == 0 {
+		return 0
+	}
+
+	node := v.doc.Content[0]
+	line := node.Line
+	for _, p := range path {
+		var next *yaml.Node
+		switch p := p.(type) {
+		case string:
+			if node.Kind != yaml.MappingNode {
+				return line
+			}
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				if node.Content[i].Value == p {
+					line = node.Content[i].Line
+					next = node.Content[i+1]
+					break
+				}
+			}
+		case int:
+			if node.Kind != yaml.SequenceNode || p >= len(node.Content) {
+				return line
+			}
+			next = node.Content[p]
+			line = next.Line
+		}
+		if next == nil {
+			return line
+		}
+		node = next
+	}
+
+	return line
+}
+
+// Validate checks the template for problems that would otherwise only be found when it is rendered. Problems with the
+// template are returned as diagnostics, an error means it couldn't be checked at all
+func (s *Skeley) Validate() ([]Diagnostic, error) {
+	v := &validator{diags: []Diagnostic{}}
+
+	ok, err := isTemplateDir(s.inputFS, ".")
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%v is not a template", s.conf.Template)
+	}
+
+	if !v.checkConfig(s.inputFS) {
+		return v.diags, nil
+	}
+
+	tmpl, err := s.loadTemplate()
+	if err != nil {
+		v.add("config.yaml", v.configLine("extends"), "%v", err)
+		return v.diags, nil
+	}
+
+	conf, err := readTemplateConfig(s.inputFS)
+	if err != nil {
+		return nil, err
+	}
+
+	v.checkVariables(conf.Variables)
+
+	declared := map[string]bool{}
+	for _, name := range legacyBuiltins {
+		declared[name] = true
+	}
+	for _, d := range tmpl.conf.Variables {
+		declared[d.Name] = true
+	}
+	funcMap := templateFuncs()
+
+	if err := v.checkPartials(s.inputFS, funcMap, declared); err != nil {
+		return nil, err
+	}
+
+	names, err := v.checkFiles(s.inputFS, tmpl, funcMap, declared)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, pattern := range conf.CopyOnly {
+		v.checkGlob(names, pattern, v.configLine("copy-only", i))
+	}
+	for i, pattern := range conf.Raw {
+		v.checkGlob(names, pattern, v.configLine("raw", i))
+	}
+
+	patterns := make([]string, 0, len(conf.Conditions))
+	for pattern := range conf.Conditions {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		line := v.configLine("conditions", pattern)
+		v.checkGlob(names, pattern, line)
+
+		t, err := template.New(pattern).Funcs(funcMap).Parse(conf.Conditions[pattern])
+		if err != nil {
+			v.add("config.yaml", line, "condition for %v: %v", pattern, templateErrMessage(err))
+			continue
+		}
+		v.checkFields(t, declared, func(_ int, msg string) {
+			v.add("config.yaml", line, "condition for %v: %v", pattern, msg)
+		})
+	}
+
+	sort.SliceStable(v.diags, func(i, j int) bool {
+		if v.diags[i].File != v.diags[j].File {
+			return v.diags[i].File < v.diags[j].File
+		}
+		return v.diags[i].Line < v.diags[j].Line
+	})
+
+	return v.diags, nil
+}
+
+// checkConfig parses config.yaml strictly, reporting if it could be parsed at all
+func (v *validator) checkConfig(fsys fs.FS) bool {
+	content, err := fs.ReadFile(fsys, "config.yaml")
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return true
+		}
+		v.add("config.yaml", 0, "%v", err)
+		return false
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		line, msg := yamlErrMessage(err.Error())
+		v.add("config.yaml", line, "%v", msg)
+		return false
+	}
+	v.doc = &doc
+
+	dec := yaml.NewDecoder(bytes.NewReader(content))
+	dec.KnownFields(true)
+	var conf templateConfig
+	err = dec.Decode(&conf)
+
+	var typeErr *yaml.TypeError
+	switch {
+	case err == nil:
+	case errors.As(err, &typeErr):
+		for _, e := range typeErr.Errors {
+			line, msg := yamlErrMessage(e)
+			if m := yamlUnknownKey.FindStringSubmatch(msg); m != nil {
+				msg = fmt.Sprintf("unknown key %v", m[1])
+			}
+			v.add("config.yaml", line, "%v", msg)
+		}
+	default:
+		v.add("config.yaml", 0, "%v", err)
+	}
+
+	// Unknown keys are reported, but the rest of the config can still be checked
+	_, err = readTemplateConfig(fsys)
+	return err == nil
+}
+
+func (v *validator) checkVariables(decls []templateVariable) {
+	declared := map[string]any{}
+	for i, d := range decls {
+		line := v.configLine("variables", i)
+		if err := checkDeclaration(d, declared); err != nil {
+			v.add("config.yaml", line, "%v", err)
+			continue
+		}
+		declared[d.Name] = true
+
+		if d.Validate != "" {
+			if _, err := regexp.Compile(d.Validate); err != nil {
+				v.add("config.yaml", line, "variable %q: invalid validate pattern: %v", d.Name, err)
+				continue
+			}
+		}
+		if d.Default == nil {
+			continue
+		}
+
+		val, err := coerceValue(d.varType(), d.Default)
+		if err != nil {
+			v.add("config.yaml", line, "variable %q: default: %v", d.Name, err)
+			continue
+		}
+		if err := d.validate(val); err != nil {
+			v.add("config.yaml", line, "variable %q: default: %v", d.Name, err)
+		}
+	}
+}
+
+func (v *validator) checkPartials(fsys fs.FS, funcMap template.FuncMap, declared map[string]bool) error {
+	if _, err := fs.Stat(fsys, "partials"); err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil
+		}
+		return err
+	}
+
+	return fs.WalkDir(fsys, "partials", func(name string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return err
+		}
+		v.checkTemplate(name, string(content), funcMap, declared)
+		return nil
+	})
+}
+
+// checkFiles checks the template's own files, returning the names of every file it outputs, including those from its
+// parents
+func (v *validator) checkFiles(fsys fs.FS, tmpl loadedTemplate, funcMap template.FuncMap, declared map[string]bool) ([]string, error) {
+	copyPatterns := tmpl.conf.copyOnlyPatterns()
+	names := []string{}
+
+	for i, layer := range tmpl.layers {
+		own := i == len(tmpl.layers)-1
+		if _, err := fs.Stat(layer, "files"); err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, err
+		}
+
+		err := fs.WalkDir(layer, "files", func(name string, d fs.DirEntry, err error) error {
+			if err != nil || d.IsDir() {
+				return err
+			}
+
+			rel := strings.TrimPrefix(name, "files/")
+			if !contains(names, rel) {
+				names = append(names, rel)
+			}
+			if !own {
+				return nil
+			}
+
+			for _, seg := range strings.Split(rel, "/") {
+				if !strings.Contains(seg, "{{") {
+					continue
+				}
+				t, err := template.New(seg).Funcs(funcMap).Parse(seg)
+				if err != nil {
+					v.add(name, 0, "path: %v", templateErrMessage(err))
+					continue
+				}
+				v.checkFields(t, declared, func(_ int, msg string) {
+					v.add(name, 0, "path: %v", msg)
+				})
+			}
+
+			copyOnly, err := matchAnyGlob(copyPatterns, rel)
+			if err != nil || copyOnly {
+				return err
+			}
+			content, err := fs.ReadFile(layer, name)
+			if err != nil {
+				return err
+			}
+			if isBinary(content) {
+				return nil
+			}
+
+			v.checkTemplate(name, string(content), funcMap, declared)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return names, nil
+}
+
+func (v *validator) checkTemplate(name string, content string, funcMap template.FuncMap, declared map[string]bool) {
+	t, err := template.New(name).Funcs(funcMap).Parse(content)
+	if err != nil {
+		line, msg := templateErrLineMessage(err)
+		v.add(name, line, "%v", msg)
+		return
+	}
+
+	v.checkFields(t, declared, func(line int, msg string) {
+		v.add(name, line, "%v", msg)
+	})
+}
+
+func (v *validator) checkGlob(names []string, pattern string, line int) {
+	for _, name := range names {
+		ok, err := matchGlob(pattern, name)
+		if err != nil {
+			v.add("config.yaml", line, "invalid pattern %v: %v", pattern, err)
+			return
+		}
+		if ok {
+			return
+		}
+	}
+	v.add("config.yaml", line, "pattern %v doesn't match any files", pattern)
+}
+
+// checkFields reports references to fields of the render context that aren't declared variables or builtins. Only
+// references made with the root context are checked, as `range` and `with` change what `.` refers to
+func (v *validator) checkFields(t *template.Template, declared map[string]bool, report func(line int, msg string)) {
+	builtins := map[string]bool{}
+	typ := reflect.TypeOf(templateVars{})
+	for i := 0; i < typ.NumField(); i++ {
+		builtins[typ.Field(i).Name] = true
+	}
+
+	for _, tmpl := range t.Templates() {
+		if tmpl.Tree == nil || tmpl.Tree.Root == nil {
+			continue
+		}
+		tree := tmpl.Tree
+
+		check := func(node parse.Node, ident []string) {
+			var msg string
+			switch {
+			case ident[0] == BuiltinNamespace:
+				if len(ident) > 1 && !builtins[ident[1]] {
+					msg = fmt.Sprintf("unknown builtin .%v.%v", BuiltinNamespace, ident[1])
+				}
+			case !declared[ident[0]]:
+				msg = fmt.Sprintf("undeclared variable .%v", ident[0])
+			}
+			if msg == "" {
+				return
+			}
+
+			location, _ := tree.ErrorContext(node)
+			report(locationLine(location), msg)
+		}
+
+		var walk func(node parse.Node, root bool)
+		walk = func(node parse.Node, root bool) {
+			switch n := node.(type) {
+			case *parse.ListNode:
+				if n == nil {
+					return
+				}
+				for _, child := range n.Nodes {
+					walk(child, root)
+				}
+			case *parse.ActionNode:
+				walk(n.Pipe, root)
+			case *parse.PipeNode:
+				if n == nil {
+					return
+				}
+				for _, cmd := range n.Cmds {
+					walk(cmd, root)
+				}
+			case *parse.CommandNode:
+				for _, arg := range n.Args {
+					walk(arg, root)
+				}
+			case *parse.ChainNode:
+				walk(n.Node, root)
+			case *parse.FieldNode:
+				if root {
+					check(n, n.Ident)
+				}
+			case *parse.VariableNode:
+				if n.Ident[0] == "$" && len(n.Ident) > 1 {
+					check(n, n.Ident[1:])
+				}
+			case *parse.IfNode:
+				walk(n.Pipe, root)
+				walk(n.List, root)
+				walk(n.ElseList, root)
+			case *parse.RangeNode:
+				walk(n.Pipe, root)
+				walk(n.List, false)
+				walk(n.ElseList, root)
+			case *parse.WithNode:
+				walk(n.Pipe, root)
+				walk(n.List, false)
+				walk(n.ElseList, root)
+			case *parse.TemplateNode:
+				walk(n.Pipe, root)
+			}
+		}
+		walk(tree.Root, true)
+	}
+}
+
+// yamlErrMessage splits the line number from a yaml error message
+func yamlErrMessage(msg string) (int, string) {
+	m := yamlErrLine.FindStringSubmatch(msg)
+	if m == nil {
+		return 0, strings.TrimPrefix(msg, "yaml: ")
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line, m[2]
+}
+
+// templateErrLineMessage splits the line number from a template parse error
+func templateErrLineMessage(err error) (int, string) {
+	m := templateErrLine.FindStringSubmatch(err.Error())
+	if m == nil {
+		return 0, err.Error()
+	}
+	line, _ := strconv.Atoi(m[1])
+	return line, m[2]
+}
+
+func templateErrMessage(err error) string {
+	_, msg := templateErrLineMessage(err)
+	return msg
+}
+
+// locationLine is the line from a `name:line:col` template location
+func locationLine(location string) int {
+	parts := strings.Split(location, ":")
+	if len(parts) < 3 {
+		return 0
+	}
+	line, _ := strconv.Atoi(parts[len(parts)-2])
+	return line
+}
@@ -0,0 +1,147 @@
+package internal
+
+import (
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidate(t *testing.T) {
+	newSource := func(t *testing.T) *memfs.FS {
+		src := memfs.New()
+
+		require.NoError(t, src.MkdirAll("base/files", 0775))
+		require.NoError(t, src.WriteFile("base/config.yaml", []byte(dedent.Dedent(`
+			not-module: true
+			variables:
+			  - name: Owner
+		`)), 0664))
+		require.NoError(t, src.WriteFile("base/files/OWNERS", []byte("{{ .Owner }}\n"), 0664))
+
+		require.NoError(t, src.MkdirAll("good/files/cmd", 0775))
+		require.NoError(t, src.MkdirAll("good/partials", 0775))
+		require.NoError(t, src.WriteFile("good/config.yaml", []byte(dedent.Dedent(`
+			extends: base
+			copy-only:
+			  - "*.png"
+			conditions:
+			  Dockerfile: "{{ .Docker }}"
+			variables:
+			  - name: Docker
+			    type: bool
+			  - name: Services
+			    type: list
+			    default: [api]
+		`)), 0664))
+		require.NoError(t, src.WriteFile("good/partials/header.tmpl", []byte("// {{ .Skeley.Module }}\n"), 0664))
+		require.NoError(t, src.WriteFile("good/files/Dockerfile", []byte(`FROM golang:{{ replace "go" "" .Skeley.Toolchain }}`+"\n"), 0664))
+		require.NoError(t, src.WriteFile("good/files/logo.png", []byte("{{ not a template"), 0664))
+		require.NoError(t, src.WriteFile("good/files/cmd/{{ .BinaryName }}.go", []byte(dedent.Dedent(`
+			{{- template "header" . -}}
+			package main
+			// {{ .Owner }} {{ $.Module }}
+			{{ range .Services }}{{ .Name }} {{ $.Docker }}{{ end }}
+			{{ with .Owner }}{{ .Anything }}{{ end }}
+		`)), 0664))
+
+		require.NoError(t, src.MkdirAll("bad/files", 0775))
+		require.NoError(t, src.MkdirAll("bad/partials", 0775))
+		require.NoError(t, src.WriteFile("bad/config.yaml", []byte(dedent.Dedent(`
+			not-module: true
+			copyonly:
+			  - "*.bin"
+			raw:
+			  - "*.png"
+			conditions:
+			  Dockerfile: "{{ .UseDocker }}"
+			variables:
+			  - name: Port
+			    type: int
+			    default: eighty
+			  - name: Skeley
+			  - name: Port
+			  - name: Name
+			    validate: "[a-z"
+		`)), 0664))
+		require.NoError(t, src.WriteFile("bad/partials/footer.tmpl", []byte("{{ .Footer }}\n"), 0664))
+		require.NoError(t, src.WriteFile("bad/files/main.go", []byte(dedent.Dedent(`
+			package main
+
+			// {{ .Port }} {{ .Skeley.Nope }}
+			// {{ if .Debug }}{{ $.Verbose }}{{ end }}
+			// {{ .MajorVersion }} {{ .Skeley.MajorVersion }}
+		`)[1:]), 0664))
+		require.NoError(t, src.WriteFile("bad/files/broken.txt", []byte("line one\n{{ nope }}\n"), 0664))
+		require.NoError(t, src.WriteFile("bad/files/{{ .Dir }}.txt", []byte(""), 0664))
+
+		return src
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		diags, err := newTemplateSkeley(t, newSource(t), "good").Validate()
+		require.NoError(t, err)
+		require.Empty(t, diags)
+	})
+
+	t.Run("problems", func(t *testing.T) {
+		diags, err := newTemplateSkeley(t, newSource(t), "bad").Validate()
+		require.NoError(t, err)
+
+		strs := []string{}
+		for _, d := range diags {
+			strs = append(strs, d.String())
+		}
+		require.Equal(
+			t,
+			[]string{
+				"config.yaml:3: unknown key copyonly",
+				`config.yaml:6: pattern *.png doesn't match any files`,
+				`config.yaml:8: pattern Dockerfile doesn't match any files`,
+				"config.yaml:8: condition for Dockerfile: undeclared variable .UseDocker",
+				`config.yaml:10: variable "Port": default: invalid int "eighty"`,
+				`config.yaml:13: variable name "Skeley" is reserved`,
+				`config.yaml:14: variable "Port" declared more than once`,
+				`config.yaml:15: variable "Name": invalid validate pattern: error parsing regexp: missing closing ]: ` + "`[a-z`",
+				`files/broken.txt:2: function "nope" not defined`,
+				"files/main.go:3: unknown builtin .Skeley.Nope",
+				"files/main.go:4: undeclared variable .Debug",
+				"files/main.go:4: undeclared variable .Verbose",
+				"files/main.go:5: undeclared variable .MajorVersion",
+				"files/{{ .Dir }}.txt: path: undeclared variable .Dir",
+				"partials/footer.tmpl:1: undeclared variable .Footer",
+			},
+			strs,
+		)
+	})
+
+	t.Run("invalid yaml", func(t *testing.T) {
+		src := newSource(t)
+		require.NoError(t, src.WriteFile("bad/config.yaml", []byte("variables:\n  - name: [\n"), 0664))
+
+		diags, err := newTemplateSkeley(t, src, "bad").Validate()
+		require.NoError(t, err)
+		require.Len(t, diags, 1)
+		require.Equal(t, "config.yaml", diags[0].File)
+		require.Equal(t, 2, diags[0].Line)
+		require.Equal(t, "did not find expected node content", diags[0].Message)
+	})
+
+	t.Run("missing parent", func(t *testing.T) {
+		src := newSource(t)
+		require.NoError(t, src.WriteFile("bad/config.yaml", []byte("not-module: true\nextends: nope\n"), 0664))
+
+		diags, err := newTemplateSkeley(t, src, "bad").Validate()
+		require.NoError(t, err)
+		require.Equal(t, []Diagnostic{{File: "config.yaml", Line: 2, Message: "parent template nope not found"}}, diags)
+	})
+
+	t.Run("not a template", func(t *testing.T) {
+		src := newSource(t)
+		require.NoError(t, src.MkdirAll("docs", 0775))
+
+		_, err := newTemplateSkeley(t, src, "docs").Validate()
+		require.EqualError(t, err, "docs is not a template")
+	})
+}
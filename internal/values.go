@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// VariableEnvPrefix is prepended to the upper cased variable name to find its value in the environment
+const VariableEnvPrefix = "SKELEY_VAR_"
+
+// LoadValues reads each values file in order, followed by `key=value` pairs, with later values taking precedence.
+// Values files may be YAML or JSON
+func LoadValues(valueFiles []string, sets []string) (map[string]any, error) {
+	values := map[string]any{}
+
+	for _, path := range valueFiles {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("error reading values file: %w", err)
+		}
+
+		fileValues := map[string]any{}
+		if err := yaml.Unmarshal(content, &fileValues); err != nil {
+			return nil, fmt.Errorf("error parsing values file %v: %w", path, err)
+		}
+
+		for k, v := range fileValues {
+			values[k] = v
+		}
+	}
+
+	for _, s := range sets {
+		key, val, ok := strings.Cut(s, "=")
+		if !ok || key == "" {
+			return nil, fmt.Errorf("invalid value %q, expected key=value", s)
+		}
+		values[key] = val
+	}
+
+	return values, nil
+}
+
+func variableEnvName(name string) string {
+	return VariableEnvPrefix + strings.ToUpper(name)
+}
+
+// envValues looks up a value in the environment for each declared variable
+func envValues(decls []templateVariable) map[string]any {
+	values := map[string]any{}
+	for _, d := range decls {
+		if val, ok := os.LookupEnv(variableEnvName(d.Name)); ok {
+			values[d.Name] = val
+		}
+	}
+	return values
+}
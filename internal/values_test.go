@@ -0,0 +1,128 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadValues(t *testing.T) {
+	t.Run("precedence", func(t *testing.T) {
+		dir := t.TempDir()
+		first := filepath.Join(dir, "first.yaml")
+		second := filepath.Join(dir, "second.json")
+		require.NoError(t, os.WriteFile(first, []byte(dedent.Dedent(`
+			Service: first
+			Port: 1
+			Owners: [a, b]
+		`)), 0664))
+		require.NoError(t, os.WriteFile(second, []byte(`{"Port": 2, "UseDocker": true}`), 0664))
+
+		values, err := LoadValues([]string{first, second}, []string{"UseDocker=false", "Extra=a=b"})
+		require.NoError(t, err)
+		require.Equal(
+			t,
+			map[string]any{
+				"Service":   "first",
+				"Port":      2,
+				"Owners":    []any{"a", "b"},
+				"UseDocker": "false",
+				"Extra":     "a=b",
+			},
+			values,
+		)
+	})
+
+	t.Run("invalid set", func(t *testing.T) {
+		_, err := LoadValues(nil, []string{"novalue"})
+		require.EqualError(t, err, `invalid value "novalue", expected key=value`)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := LoadValues([]string{filepath.Join(t.TempDir(), "nope.yaml")}, nil)
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+}
+
+func TestExecuteValues(t *testing.T) {
+	newInput := func(t *testing.T) *memfs.FS {
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/out.txt", []byte("{{ .Service }}:{{ .Port }}"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				not-module: true
+				variables:
+				  - name: Service
+				    required: true
+				  - name: Port
+				    type: int
+				    default: 8080
+			`)),
+			0664,
+		))
+		return inpFS
+	}
+
+	t.Run("values override defaults", func(t *testing.T) {
+		dir := t.TempDir()
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			Values: map[string]any{
+				"Service": "api",
+				"Port":    "9090",
+			},
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "api:9090", string(content))
+	})
+
+	t.Run("env overrides values", func(t *testing.T) {
+		dir := t.TempDir()
+		t.Setenv("SKELEY_VAR_PORT", "7070")
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: dir,
+			Values: map[string]any{
+				"Service": "api",
+				"Port":    "9090",
+			},
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "api:7070", string(content))
+	})
+
+	t.Run("missing required", func(t *testing.T) {
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: t.TempDir(),
+		})
+		require.ErrorContains(t, sk.Execute(), `missing value for required variable "Service"`)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    newInput(t),
+			OutputPath: t.TempDir(),
+			Values: map[string]any{
+				"Service": "api",
+				"Port":    "eighty",
+			},
+		})
+		require.EqualError(t, sk.Execute(), `variable "Port": invalid int "eighty"`)
+	})
+}
@@ -0,0 +1,254 @@
+package internal
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+//go:generate go-enum -f $GOFILE -marshal -names
+
+/*
+ENUM(
+string
+bool
+int
+list
+)
+*/
+type VariableType string
+
+// BuiltinNamespace is the key in the render context that always holds the builtin (go.mod derived) values, regardless
+// of what variables the template declares
+const BuiltinNamespace = "Skeley"
+
+type templateVariable struct {
+	Name        string       `yaml:"name"`
+	Type        VariableType `yaml:"type,omitempty"`
+	Description string       `yaml:"description,omitempty"`
+	Default     any          `yaml:"default,omitempty"`
+	Required    bool         `yaml:"required,omitempty"`
+	// Choices restricts the allowed values, for string and list variables
+	Choices []string `yaml:"choices,omitempty"`
+	// Validate is a regular expression values must match, each item is matched for list variables
+	Validate string `yaml:"validate,omitempty"`
+	// Secret masks the value when prompting
+	Secret bool `yaml:"secret,omitempty"`
+}
+
+func (v templateVariable) varType() VariableType {
+	if v.Type == "" {
+		return VariableTypeString
+	}
+	return v.Type
+}
+
+// validateString checks a single (string form of a) value against the validation pattern and choices
+func (v templateVariable) validateString(val string) error {
+	if v.Validate != "" {
+		re, err := regexp.Compile(v.Validate)
+		if err != nil {
+			return fmt.Errorf("invalid validate pattern: %w", err)
+		}
+		if !re.MatchString(val) {
+			return fmt.Errorf("%q does not match %v", val, v.Validate)
+		}
+	}
+
+	if len(v.Choices) > 0 && !contains(v.Choices, val) {
+		return fmt.Errorf("%q is not one of %v", val, strings.Join(v.Choices, ", "))
+	}
+
+	return nil
+}
+
+func (v templateVariable) validate(val any) error {
+	switch typed := val.(type) {
+	case []string:
+		for _, item := range typed {
+			if err := v.validateString(item); err != nil {
+				return err
+			}
+		}
+		return nil
+	case bool:
+		return nil
+	default:
+		return v.validateString(fmt.Sprint(val))
+	}
+}
+
+// templateVars are the builtins under BuiltinNamespace. They mirror moduleInfo, see it for what each field holds
+type templateVars struct {
+	Module       string
+	BinaryName   string
+	GoVersion    string
+	GoMinor      int
+	Toolchain    string
+	Host         string
+	Owner        string
+	Repo         string
+	MajorVersion string
+	Requires     []ModuleRequire
+	Replaces     []ModuleReplace
+}
+
+// renderContext builds the data passed to every template. Builtins are always reachable under BuiltinNamespace, and
+// are also exposed at the top level for backwards compatibility unless a declared variable shadows them
+func renderContext(builtins templateVars, values map[string]any) map[string]any {
+	ctx := map[string]any{
+		"Module":     builtins.Module,
+		"BinaryName": builtins.BinaryName,
+		"GoVersion":  builtins.GoVersion,
+	}
+	for k, v := range values {
+		ctx[k] = v
+	}
+	ctx[BuiltinNamespace] = builtins
+
+	return ctx
+}
+
+// resolveVariables produces the final value for each declared variable, falling back to its default and then to the
+// zero value for its type
+func resolveVariables(decls []templateVariable, values map[string]any) (map[string]any, error) {
+	out := map[string]any{}
+
+	for _, d := range decls {
+		if err := checkDeclaration(d, out); err != nil {
+			return nil, err
+		}
+
+		raw, ok := values[d.Name]
+		if !ok || raw == nil {
+			raw = d.Default
+		}
+		if raw == nil {
+			if d.Required {
+				return nil, fmt.Errorf(
+					"missing value for required variable %q, supply it with --set %v=<value>, a values file, or $%v",
+					d.Name,
+					d.Name,
+					variableEnvName(d.Name),
+				)
+			}
+			out[d.Name] = zeroValue(d.varType())
+			continue
+		}
+
+		val, err := coerceValue(d.varType(), raw)
+		if err != nil {
+			return nil, fmt.Errorf("variable %q: %w", d.Name, err)
+		}
+		if err := d.validate(val); err != nil {
+			return nil, fmt.Errorf("variable %q: %w", d.Name, err)
+		}
+		out[d.Name] = val
+	}
+
+	return out, nil
+}
+
+// checkDeclaration checks a variable can be declared, given the variables declared before it
+func checkDeclaration(d templateVariable, declared map[string]any) error {
+	if d.Name == "" {
+		return fmt.Errorf("variable declared without a name")
+	}
+	if d.Name == BuiltinNamespace {
+		return fmt.Errorf("variable name %q is reserved", d.Name)
+	}
+	if _, ok := declared[d.Name]; ok {
+		return fmt.Errorf("variable %q declared more than once", d.Name)
+	}
+	if !d.varType().IsValid() {
+		return fmt.Errorf("variable %q: %w", d.Name, ErrInvalidVariableType)
+	}
+	return nil
+}
+
+func zeroValue(typ VariableType) any {
+	switch typ {
+	case VariableTypeBool:
+		return false
+	case VariableTypeInt:
+		return 0
+	case VariableTypeList:
+		return []string{}
+	default:
+		return ""
+	}
+}
+
+// coerceValue converts a value into the go type for the variable type. Strings are parsed, so values supplied on the
+// command line can be used for any type
+func coerceValue(typ VariableType, raw any) (any, error) {
+	switch typ {
+	case VariableTypeString:
+		switch v := raw.(type) {
+		case string:
+			return v, nil
+		case bool, int, int64, float64:
+			return fmt.Sprint(v), nil
+		}
+	case VariableTypeBool:
+		switch v := raw.(type) {
+		case bool:
+			return v, nil
+		case string:
+			b, err := strconv.ParseBool(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("invalid bool %q", v)
+			}
+			return b, nil
+		}
+	case VariableTypeInt:
+		switch v := raw.(type) {
+		case int:
+			return v, nil
+		case int64:
+			return int(v), nil
+		case float64:
+			if v != float64(int(v)) {
+				return nil, fmt.Errorf("invalid int %v", v)
+			}
+			return int(v), nil
+		case string:
+			i, err := strconv.Atoi(strings.TrimSpace(v))
+			if err != nil {
+				return nil, fmt.Errorf("invalid int %q", v)
+			}
+			return i, nil
+		}
+	case VariableTypeList:
+		switch v := raw.(type) {
+		case []string:
+			return v, nil
+		case []any:
+			list := make([]string, 0, len(v))
+			for _, item := range v {
+				list = append(list, fmt.Sprint(item))
+			}
+			return list, nil
+		case string:
+			list := []string{}
+			for _, item := range strings.Split(v, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					list = append(list, item)
+				}
+			}
+			return list, nil
+		}
+	}
+
+	return nil, fmt.Errorf("cannot use %T value %v as %v", raw, raw, typ)
+}
+
+func contains(list []string, val string) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+// Code generated by go-enum DO NOT EDIT.
+// Version: 0.5.8
+// Revision: 3d844c8ecc59661ed7aa17bfd65727bc06a60ad8
+// Build Date: 2023-09-18T14:55:21Z
+// Built By: goreleaser
+
+package internal
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// VariableTypeString is a VariableType of type string.
+	VariableTypeString VariableType = "string"
+	// VariableTypeBool is a VariableType of type bool.
+	VariableTypeBool VariableType = "bool"
+	// VariableTypeInt is a VariableType of type int.
+	VariableTypeInt VariableType = "int"
+	// VariableTypeList is a VariableType of type list.
+	VariableTypeList VariableType = "list"
+)
+
+var ErrInvalidVariableType = fmt.Errorf("not a valid VariableType, try [%s]", strings.Join(_VariableTypeNames, ", "))
+
+var _VariableTypeNames = []string{
+	string(VariableTypeString),
+	string(VariableTypeBool),
+	string(VariableTypeInt),
+	string(VariableTypeList),
+}
+
+// VariableTypeNames returns a list of possible string values of VariableType.
+func VariableTypeNames() []string {
+	tmp := make([]string, len(_VariableTypeNames))
+	copy(tmp, _VariableTypeNames)
+	return tmp
+}
+
+// String implements the Stringer interface.
+func (x VariableType) String() string {
+	return string(x)
+}
+
+// IsValid provides a quick way to determine if the typed value is
+// part of the allowed enumerated values
+func (x VariableType) IsValid() bool {
+	_, err := ParseVariableType(string(x))
+	return err == nil
+}
+
+var _VariableTypeValue = map[string]VariableType{
+	"string": VariableTypeString,
+	"bool":   VariableTypeBool,
+	"int":    VariableTypeInt,
+	"list":   VariableTypeList,
+}
+
+// ParseVariableType attempts to convert a string to a VariableType.
+func ParseVariableType(name string) (VariableType, error) {
+	if x, ok := _VariableTypeValue[name]; ok {
+		return x, nil
+	}
+	return VariableType(""), fmt.Errorf("%s is %w", name, ErrInvalidVariableType)
+}
+
+// MarshalText implements the text marshaller method.
+func (x VariableType) MarshalText() ([]byte, error) {
+	return []byte(string(x)), nil
+}
+
+// UnmarshalText implements the text unmarshaller method.
+func (x *VariableType) UnmarshalText(text []byte) error {
+	tmp, err := ParseVariableType(string(text))
+	if err != nil {
+		return err
+	}
+	*x = tmp
+	return nil
+}
@@ -0,0 +1,297 @@
+package internal
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/lithammer/dedent"
+	"github.com/psanford/memfs"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+func TestResolveVariables(t *testing.T) {
+	testData := []struct {
+		name     string
+		decls    []templateVariable
+		values   map[string]any
+		expected map[string]any
+		err      string
+	}{
+		{
+			name: "defaults",
+			decls: []templateVariable{
+				{Name: "Service", Default: "api"},
+				{Name: "Port", Type: VariableTypeInt, Default: 8080},
+				{Name: "UseDocker", Type: VariableTypeBool, Default: true},
+				{Name: "Owners", Type: VariableTypeList, Default: []any{"a", "b"}},
+			},
+			expected: map[string]any{
+				"Service":   "api",
+				"Port":      8080,
+				"UseDocker": true,
+				"Owners":    []string{"a", "b"},
+			},
+		},
+		{
+			name: "zero values",
+			decls: []templateVariable{
+				{Name: "Service"},
+				{Name: "Port", Type: VariableTypeInt},
+				{Name: "UseDocker", Type: VariableTypeBool},
+				{Name: "Owners", Type: VariableTypeList},
+			},
+			expected: map[string]any{
+				"Service":   "",
+				"Port":      0,
+				"UseDocker": false,
+				"Owners":    []string{},
+			},
+		},
+		{
+			name: "values override defaults",
+			decls: []templateVariable{
+				{Name: "Port", Type: VariableTypeInt, Default: 8080},
+			},
+			values: map[string]any{
+				"Port": "9090",
+			},
+			expected: map[string]any{
+				"Port": 9090,
+			},
+		},
+		{
+			name: "string coercion",
+			decls: []templateVariable{
+				{Name: "UseDocker", Type: VariableTypeBool},
+				{Name: "Owners", Type: VariableTypeList},
+			},
+			values: map[string]any{
+				"UseDocker": "true",
+				"Owners":    "a, b,,c",
+			},
+			expected: map[string]any{
+				"UseDocker": true,
+				"Owners":    []string{"a", "b", "c"},
+			},
+		},
+		{
+			name: "required without value",
+			decls: []templateVariable{
+				{Name: "Service", Required: true},
+			},
+			err: `missing value for required variable "Service", supply it with --set Service=<value>, a values file, or $SKELEY_VAR_SERVICE`,
+		},
+		{
+			name: "reserved name",
+			decls: []templateVariable{
+				{Name: BuiltinNamespace},
+			},
+			err: `variable name "Skeley" is reserved`,
+		},
+		{
+			name: "duplicate name",
+			decls: []templateVariable{
+				{Name: "Service"},
+				{Name: "Service"},
+			},
+			err: `variable "Service" declared more than once`,
+		},
+		{
+			name: "bad default",
+			decls: []templateVariable{
+				{Name: "Port", Type: VariableTypeInt, Default: "eighty"},
+			},
+			err: `variable "Port": invalid int "eighty"`,
+		},
+		{
+			name: "validate pattern",
+			decls: []templateVariable{
+				{Name: "Service", Validate: "^[a-z-]+$"},
+			},
+			values: map[string]any{
+				"Service": "My Service",
+			},
+			err: `variable "Service": "My Service" does not match ^[a-z-]+$`,
+		},
+		{
+			name: "validate list items",
+			decls: []templateVariable{
+				{Name: "Features", Type: VariableTypeList, Choices: []string{"grpc", "http"}},
+			},
+			values: map[string]any{
+				"Features": "grpc,soap",
+			},
+			err: `variable "Features": "soap" is not one of grpc, http`,
+		},
+	}
+	for _, tc := range testData {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := resolveVariables(tc.decls, tc.values)
+			if tc.err != "" {
+				require.EqualError(t, err, tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.expected, got)
+		})
+	}
+}
+
+func TestVariableConfig(t *testing.T) {
+	t.Run("unmarshal", func(t *testing.T) {
+		var conf templateConfig
+		require.NoError(t, yaml.Unmarshal([]byte(dedent.Dedent(`
+			variables:
+			  - name: Service
+			    description: name of the service
+			    required: true
+			  - name: Port
+			    type: int
+			    default: 8080
+		`)), &conf))
+
+		require.Equal(
+			t,
+			[]templateVariable{
+				{Name: "Service", Description: "name of the service", Required: true},
+				{Name: "Port", Type: VariableTypeInt, Default: 8080},
+			},
+			conf.Variables,
+		)
+	})
+
+	t.Run("invalid type", func(t *testing.T) {
+		var conf templateConfig
+		require.ErrorIs(t, yaml.Unmarshal([]byte("variables: [{name: Foo, type: float}]"), &conf), ErrInvalidVariableType)
+	})
+}
+
+func TestExecuteVariables(t *testing.T) {
+	t.Run("custom and builtin", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/foo/bar\n\ngo 1.20\n"), 0664))
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile(
+			"files/out.txt",
+			[]byte("{{ .Service }}:{{ .Port }} {{ .Module }} {{ .Skeley.Module }} {{ .Skeley.BinaryName }}"),
+			0664,
+		))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				variables:
+				  - name: Service
+				    default: api
+				  - name: Port
+				    type: int
+				    default: 8080
+			`)),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "api:8080 github.com/foo/bar github.com/foo/bar bar", string(content))
+	})
+
+	t.Run("custom shadows top level builtin", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte("module github.com/foo/bar\n\ngo 1.20\n"), 0664))
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/out.txt", []byte("{{ .BinaryName }} {{ .Skeley.BinaryName }}"), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				variables:
+				  - name: BinaryName
+				    default: custom
+			`)),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "custom bar", string(content))
+	})
+
+	t.Run("module details", func(t *testing.T) {
+		dir := t.TempDir()
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "go.mod"), []byte(dedent.Dedent(`
+			module github.com/foo/bar/v2
+
+			go 1.21.1
+
+			toolchain go1.21.4
+
+			require github.com/spf13/cobra v1.6.1
+		`)), 0664))
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile(
+			"files/out.txt",
+			[]byte(dedent.Dedent(`
+				{{ .Skeley.Host }} {{ .Skeley.Owner }} {{ .Skeley.Repo }} {{ .Skeley.MajorVersion }} {{ .BinaryName }}
+				{{ .Skeley.Toolchain }}{{ if ge .Skeley.GoMinor 21 }} slog{{ end }}
+				{{ range .Skeley.Requires }}{{ .Path }}@{{ .Version }}{{ end }}
+			`)[1:]),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "out.txt"))
+		require.NoError(t, err)
+		require.Equal(t, "github.com foo bar v2 bar\ngo1.21.4 slog\ngithub.com/spf13/cobra@v1.6.1\n", string(content))
+	})
+
+	t.Run("special characters are verbatim", func(t *testing.T) {
+		dir := t.TempDir()
+
+		inpFS := memfs.New()
+		require.NoError(t, inpFS.MkdirAll("files", 0775))
+		require.NoError(t, inpFS.WriteFile("files/run.sh", []byte(`echo "{{ .Cmd }}"`), 0664))
+		require.NoError(t, inpFS.WriteFile(
+			"config.yaml",
+			[]byte(dedent.Dedent(`
+				not-module: true
+				variables:
+				  - name: Cmd
+				    default: "a < b && c > 'd'"
+			`)),
+			0664,
+		))
+
+		sk := NewSkeley(SkeleyConfig{
+			InputFS:    inpFS,
+			OutputPath: dir,
+		})
+		require.NoError(t, sk.Execute())
+
+		content, err := os.ReadFile(filepath.Join(dir, "run.sh"))
+		require.NoError(t, err)
+		require.Equal(t, `echo "a < b && c > 'd'"`, string(content))
+	})
+}
@@ -7,14 +7,11 @@ import (
 	"github.com/nicjohnson145/skeley/cmd"
 )
 
-// Version info set by goreleaser
-var (
-	version = "development"
-	date    = "unknown"
-)
+// version is set by goreleaser, and recorded in the manifest of generated projects
+var version = "development"
 
 func main() {
-	if err := cmd.Root().Execute(); err != nil {
+	if err := cmd.Root(version).Execute(); err != nil {
 		fmt.Println(err.Error())
 		os.Exit(1)
 	}